@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// backstageEntity is an entity of Backstage software catalog,
+// see https://backstage.io/docs/features/software-catalog/descriptor-format.
+type backstageEntity struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   backstageMetadata `yaml:"metadata"`
+	Spec       interface{}       `yaml:"spec"`
+}
+
+type backstageMetadata struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type backstageGroupSpec struct {
+	Type     string           `yaml:"type"`
+	Profile  backstageProfile `yaml:"profile"`
+	Parent   string           `yaml:"parent,omitempty"`
+	Children []string         `yaml:"children"`
+	Members  []string         `yaml:"members"`
+}
+
+type backstageProfile struct {
+	DisplayName string `yaml:"displayName"`
+}
+
+type backstageUserSpec struct {
+	MemberOf []string `yaml:"memberOf"`
+}
+
+// writeBackstage writes teams as Group entities and organization members as User entities
+// in a multi-document YAML file. Groups list only direct members,
+// Backstage resolves members of child groups itself.
+func writeBackstage(w io.Writer, d data) error {
+	children := childTeams(d.Teams)
+	memberOf := map[string][]string{}
+
+	var entities []backstageEntity
+	for _, slug := range sortedKeys(d.Teams) {
+		team := d.Teams[slug]
+		cells := teamCells(d.Teams, children, slug)
+
+		spec := backstageGroupSpec{
+			Type:     "team",
+			Profile:  backstageProfile{DisplayName: team.Name},
+			Children: append([]string{}, children[slug]...),
+			Members:  []string{},
+		}
+		sort.Strings(spec.Children)
+		if _, ok := d.Teams[team.Parent]; ok {
+			spec.Parent = team.Parent
+		}
+
+		for _, member := range team.Members {
+			if cells[member.Login] == cellInherited {
+				continue
+			}
+			spec.Members = append(spec.Members, member.Login)
+			memberOf[member.Login] = append(memberOf[member.Login], slug)
+		}
+
+		entities = append(entities, backstageEntity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       "Group",
+			Metadata: backstageMetadata{
+				Name:        slug,
+				Description: team.Description,
+				Annotations: map[string]string{"github.com/team-slug": d.Org.Login + "/" + slug},
+			},
+			Spec: spec,
+		})
+	}
+
+	for _, login := range allLogins(d) {
+		spec := backstageUserSpec{MemberOf: memberOf[login]}
+		if spec.MemberOf == nil {
+			spec.MemberOf = []string{}
+		}
+
+		entities = append(entities, backstageEntity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       "User",
+			Metadata: backstageMetadata{
+				Name:        login,
+				Annotations: map[string]string{"github.com/user-login": login},
+			},
+			Spec: spec,
+		})
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	for _, entity := range entities {
+		if err := encoder.Encode(entity); err != nil {
+			return err
+		}
+	}
+
+	return encoder.Close()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestShouldWriteBackstage(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeBackstage(&b, newData(newTestOrganization())); err != nil {
+		t.Fatalf("Error writing Backstage entities: %v", err)
+	}
+
+	type entity struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name string `yaml:"name"`
+		} `yaml:"metadata"`
+		Spec struct {
+			Profile struct {
+				DisplayName string `yaml:"displayName"`
+			} `yaml:"profile"`
+			Parent   string   `yaml:"parent"`
+			Children []string `yaml:"children"`
+			Members  []string `yaml:"members"`
+			MemberOf []string `yaml:"memberOf"`
+		} `yaml:"spec"`
+	}
+
+	var entities []entity
+	decoder := yaml.NewDecoder(&b)
+	for {
+		var e entity
+		err := decoder.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error decoding Backstage entities: %v", err)
+		}
+		entities = append(entities, e)
+	}
+
+	var names []string
+	for _, e := range entities {
+		names = append(names, e.Kind+":"+e.Metadata.Name)
+	}
+
+	expected := []string{
+		"Group:test-team",
+		"Group:test-team-2",
+		"Group:test-team-3",
+		"User:test-user",
+		"User:test-user-2",
+		"User:test-user-3",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected entities to be %v, got %v", expected, names)
+	}
+
+	if got := entities[0].Spec.Profile.DisplayName; got != `Test "Team" | <1> & co` {
+		t.Errorf("Expected test-team display name, got %q", got)
+	}
+	if got := entities[0].Spec.Children; !reflect.DeepEqual(got, []string{"test-team-2"}) {
+		t.Errorf("Expected test-team children to be [test-team-2], got %v", got)
+	}
+	if got := entities[0].Spec.Members; !reflect.DeepEqual(got, []string{"test-user", "test-user-2"}) {
+		t.Errorf("Expected test-team members to be [test-user test-user-2], got %v", got)
+	}
+	if got := entities[1].Spec.Parent; got != "test-team" {
+		t.Errorf("Expected test-team-2 parent to be test-team, got %q", got)
+	}
+	if got := entities[4].Spec.MemberOf; !reflect.DeepEqual(got, []string{"test-team", "test-team-3"}) {
+		t.Errorf("Expected test-user-2 to be member of [test-team test-team-3], got %v", got)
+	}
+	if got := entities[5].Spec.MemberOf; len(got) != 0 {
+		t.Errorf("Expected test-user-3 to be member of no teams, got %v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// cluster is a team with its child teams, drawn as a nested box.
+type cluster struct {
+	Team      *Team
+	Depth     int // nesting depth, 0 for top-level teams
+	Children  []*cluster
+	Collapsed bool // team is drawn as a single node summarizing its descendants
+	Teams     int  // number of descendant teams of collapsed team
+	Members   int  // number of members of collapsed team and its descendants
+}
+
+// clusterEdge is a subset relation between two drawn teams.
+type clusterEdge struct {
+	From string // team slug
+	To   string // team slug
+	Same bool   // teams have identical members
+}
+
+// clusterLayout is a forest of top-level teams with subset relations
+// and overlaps between drawn teams.
+type clusterLayout struct {
+	Clusters []*cluster
+	Subsets  []clusterEdge
+	Overlaps []teamOverlap // overlaps of teams that are not subsets, most similar first
+}
+
+// Clusters returns teams nested into their top-level teams.
+// Teams deeper than CollapseDepth levels are collapsed into their ancestor,
+// subset relations of collapsed teams are moved to that ancestor.
+// Subset relations are reduced like in SubsetEdges,
+// overlaps of collapsed teams are moved to that ancestor too, keeping the most similar one.
+func (d data) Clusters() clusterLayout {
+	children := childTeams(d.Teams)
+	for _, slugs := range children {
+		sort.Strings(slugs)
+	}
+
+	var layout clusterLayout
+	drawnAs := map[string]string{} // slug of drawn team by team slug
+
+	var build func(slug string, depth int) *cluster
+	build = func(slug string, depth int) *cluster {
+		drawnAs[slug] = slug
+		c := &cluster{Team: d.Teams[slug], Depth: depth}
+
+		if d.CollapseDepth > 0 && depth >= d.CollapseDepth && len(children[slug]) > 0 {
+			c.Collapsed = true
+			logins := toSet(d.Teams[slug].Logins())
+			for _, descendant := range descendants(children, slug) {
+				drawnAs[descendant] = slug
+				c.Teams++
+				for _, member := range d.Teams[descendant].Members {
+					logins[member.Login] = struct{}{}
+				}
+			}
+			c.Members = len(logins)
+
+			return c
+		}
+
+		for _, child := range children[slug] {
+			if _, ok := drawnAs[child]; !ok {
+				c.Children = append(c.Children, build(child, depth+1))
+			}
+		}
+
+		return c
+	}
+
+	for _, slug := range topLevelTeams(d.Teams, children) {
+		layout.Clusters = append(layout.Clusters, build(slug, 0))
+	}
+
+	seen := map[clusterEdge]struct{}{}
+	for _, e := range d.SubsetEdges() {
+		edge := clusterEdge{From: drawnAs[e.From], To: drawnAs[e.To], Same: e.Same}
+		if _, ok := seen[edge]; ok || edge.From == edge.To {
+			continue
+		}
+		seen[edge] = struct{}{}
+		layout.Subsets = append(layout.Subsets, edge)
+	}
+
+	seenOverlaps := map[[2]string]struct{}{}
+	for _, o := range d.Overlaps {
+		if o.Subset {
+			continue // drawn as subset relation
+		}
+
+		o.Team, o.OtherTeam = drawnAs[o.Team], drawnAs[o.OtherTeam]
+		pair := [2]string{o.Team, o.OtherTeam}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		if _, ok := seenOverlaps[pair]; ok || o.Team == o.OtherTeam {
+			continue
+		}
+		seenOverlaps[pair] = struct{}{}
+		layout.Overlaps = append(layout.Overlaps, o)
+	}
+
+	return layout
+}
+
+// Indent returns indentation of the cluster in the diagram.
+func (c *cluster) Indent() string {
+	return strings.Repeat("    ", c.Depth+1)
+}
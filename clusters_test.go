@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func clusterSlugs(clusters []*cluster) []string {
+	var slugs []string
+	for _, c := range clusters {
+		slug := c.Team.Slug
+		if c.Collapsed {
+			slug += "*"
+		}
+		if c.Children != nil {
+			slug += "(" + strings.Join(clusterSlugs(c.Children), " ") + ")"
+		}
+		slugs = append(slugs, slug)
+	}
+
+	return slugs
+}
+
+func TestShouldNestClusters(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a":     {"test-user", "test-user-2", "test-user-3"},
+		"a-1":   {"test-user", "test-user-2"},
+		"a-1-1": {"test-user"},
+		"a-2":   {"test-user-3"},
+		"b":     {"test-user"},
+	})
+	teams["a-1"].Parent = "a"
+	teams["a-1-1"].Parent = "a-1"
+	teams["a-2"].Parent = "a"
+
+	layout := newData(&Organization{Teams: teams}).Clusters()
+
+	expected := []string{"a(a-1(a-1-1) a-2)", "b"}
+	if got := clusterSlugs(layout.Clusters); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected clusters to be %v, got %v", expected, got)
+	}
+
+	expectedSubsets := []clusterEdge{{"a-1-1", "b", true}}
+	if !reflect.DeepEqual(layout.Subsets, expectedSubsets) {
+		t.Errorf("Expected subsets to be %v, got %v", expectedSubsets, layout.Subsets)
+	}
+}
+
+func TestShouldCollapseClusters(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a":     {"test-user", "test-user-2", "test-user-3"},
+		"a-1":   {"test-user", "test-user-2"},
+		"a-1-1": {"test-user"},
+		"a-2":   {"test-user-3"},
+		"b":     {"test-user"},
+	})
+	teams["a-1"].Parent = "a"
+	teams["a-1-1"].Parent = "a-1"
+	teams["a-2"].Parent = "a"
+
+	d := newData(&Organization{Teams: teams})
+	d.CollapseDepth = 1
+	layout := d.Clusters()
+
+	expected := []string{"a(a-1* a-2)", "b"}
+	if got := clusterSlugs(layout.Clusters); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected clusters to be %v, got %v", expected, got)
+	}
+
+	collapsed := layout.Clusters[0].Children[0]
+	if collapsed.Teams != 1 || collapsed.Members != 2 {
+		t.Errorf("Expected a-1 to collapse 1 team with 2 members, got %d teams with %d members", collapsed.Teams, collapsed.Members)
+	}
+
+	expectedSubsets := []clusterEdge{{"a-1", "b", true}}
+	if !reflect.DeepEqual(layout.Subsets, expectedSubsets) {
+		t.Errorf("Expected subsets to be %v, got %v", expectedSubsets, layout.Subsets)
+	}
+}
+
+func TestShouldMoveOverlapsToCollapsedTeams(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a":     {"test-user", "test-user-2"},
+		"a-1":   {"test-user"},
+		"a-1-1": {"test-user"},
+		"a-2":   {"test-user-2"},
+	})
+	teams["a-1"].Parent = "a"
+	teams["a-1-1"].Parent = "a-1"
+	teams["a-2"].Parent = "a"
+
+	d := newData(&Organization{Teams: teams})
+	d.CollapseDepth = 1
+	d.Overlaps = []teamOverlap{
+		{Team: "a-1-1", OtherTeam: "a-2", Similarity: 0.6},
+		{Team: "a-1", OtherTeam: "a-2", Similarity: 0.5},
+		{Team: "a-1", OtherTeam: "a-1-1", Similarity: 0.5},
+		{Team: "a", OtherTeam: "a-2", Similarity: 0.4, Subset: true},
+	}
+
+	expected := []teamOverlap{{Team: "a-1", OtherTeam: "a-2", Similarity: 0.6}}
+	if got := d.Clusters().Overlaps; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected overlaps to be %v, got %v", expected, got)
+	}
+}
+
+func TestShouldRenderClustersTemplate(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a":     {"test-user", "test-user-2", "test-user-3"},
+		"a-1":   {"test-user", "test-user-2"},
+		"a-1-1": {"test-user"},
+		"a-2":   {"test-user-3"},
+		"b":     {"test-user"},
+	})
+	teams["a-1"].Parent = "a"
+	teams["a-1-1"].Parent = "a-1"
+	teams["a-2"].Parent = "a"
+	teams["a-1"].Name = "a <1>"
+	teams["a-2"].Name = `a "2" | {x}`
+
+	d := newData(&Organization{Teams: teams})
+	d.CollapseDepth = 1
+
+	output := filepath.Join(t.TempDir(), "graph.dot")
+	if err := renderTemplate("", builtinTemplate("dot-clusters.tmpl"), output, d); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	for _, expected := range []string{
+		"    subgraph cluster_team_a {\n        label=\"a\";\n",
+		`        "a-1" [ label="{*a \<1\>*|+1 teams|2 members}"; style=filled; fillcolor=lightgrey ]`,
+		`        "a-2" [ label="{*a \"2\" \| \{x\}*|test-user-3}"; color=red ]`,
+		"    }\n    \"b\" [ label=\"{*b*|test-user}\"; color=red ]",
+		`    "a-1" -> "b" [style=dashed; dir=none; label="="];`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Diff statuses of teams, members and edges.
+const (
+	statusAdded   = "added"
+	statusRemoved = "removed"
+	statusChanged = "changed"
+)
+
+// Diff is a difference between two organization snapshots.
+type Diff struct {
+	OldFetchedAt    time.Time        `json:"old_fetched_at"`
+	NewFetchedAt    time.Time        `json:"new_fetched_at"`
+	AddedTeams      []string         `json:"added_teams"`
+	RemovedTeams    []string         `json:"removed_teams"`
+	ReparentedTeams []ReparentedTeam `json:"reparented_teams"`
+	Memberships     []MembershipDiff `json:"memberships"`  // teams with changed members
+	WithoutTeam     WithoutTeamDiff  `json:"without_team"` // members without team
+
+	oldOrg, newOrg *Organization
+}
+
+// ReparentedTeam is a team that moved to another parent.
+// Empty parent means the team is (or was) a top-level team.
+type ReparentedTeam struct {
+	Team      string `json:"team"`
+	OldParent string `json:"old_parent"`
+	NewParent string `json:"new_parent"`
+}
+
+// MembershipDiff is a change of team members.
+type MembershipDiff struct {
+	Team        string       `json:"team"`
+	Joined      []Member     `json:"joined,omitempty"`
+	Left        []Member     `json:"left,omitempty"`
+	RoleChanges []RoleChange `json:"role_changes,omitempty"`
+}
+
+// RoleChange is a change of member role in a team.
+type RoleChange struct {
+	Login   string `json:"login"`
+	OldRole string `json:"old_role"`
+	NewRole string `json:"new_role"`
+}
+
+// WithoutTeamDiff is a change of organization members that are not in any team.
+type WithoutTeamDiff struct {
+	Entered []string `json:"entered"`
+	Left    []string `json:"left"`
+}
+
+// DiffSnapshots compares two snapshots.
+// Snapshots saved with and without members cannot be compared,
+// otherwise all members would be reported as joined or left.
+func DiffSnapshots(oldSnapshot, newSnapshot *Snapshot) (*Diff, error) {
+	if oldSnapshot.HideMembers != newSnapshot.HideMembers {
+		return nil, fmt.Errorf("only one of snapshots was saved with --hide-members, members cannot be compared")
+	}
+
+	d := DiffOrganizations(oldSnapshot.Organization, newSnapshot.Organization)
+	d.OldFetchedAt = oldSnapshot.FinishedAt
+	d.NewFetchedAt = newSnapshot.FinishedAt
+
+	return d, nil
+}
+
+// DiffOrganizations compares two states of the organization.
+func DiffOrganizations(oldOrg, newOrg *Organization) *Diff {
+	d := &Diff{oldOrg: oldOrg, newOrg: newOrg}
+
+	for _, slug := range sortedKeys(oldOrg.Teams, newOrg.Teams) {
+		oldTeam, inOld := oldOrg.Teams[slug]
+		newTeam, inNew := newOrg.Teams[slug]
+
+		switch {
+		case !inOld:
+			d.AddedTeams = append(d.AddedTeams, slug)
+			oldTeam = &Team{}
+		case !inNew:
+			d.RemovedTeams = append(d.RemovedTeams, slug)
+			newTeam = &Team{}
+		case oldTeam.Parent != newTeam.Parent:
+			d.ReparentedTeams = append(d.ReparentedTeams, ReparentedTeam{
+				Team:      slug,
+				OldParent: oldTeam.Parent,
+				NewParent: newTeam.Parent,
+			})
+		}
+
+		if m := diffMembers(slug, oldTeam.Members, newTeam.Members); m != nil {
+			d.Memberships = append(d.Memberships, *m)
+		}
+	}
+
+	oldWithoutTeam := toSet(FindMembersWithoutTeam(oldOrg.Teams, oldOrg.Members))
+	newWithoutTeam := toSet(FindMembersWithoutTeam(newOrg.Teams, newOrg.Members))
+	d.WithoutTeam.Entered = setDifference(newWithoutTeam, oldWithoutTeam)
+	d.WithoutTeam.Left = setDifference(oldWithoutTeam, newWithoutTeam)
+
+	return d
+}
+
+// Empty reports whether there are no changes.
+func (d *Diff) Empty() bool {
+	return len(d.AddedTeams) == 0 &&
+		len(d.RemovedTeams) == 0 &&
+		len(d.ReparentedTeams) == 0 &&
+		len(d.Memberships) == 0 &&
+		len(d.WithoutTeam.Entered) == 0 &&
+		len(d.WithoutTeam.Left) == 0
+}
+
+func diffMembers(team string, oldMembers, newMembers []Member) *MembershipDiff {
+	oldRoles := make(map[string]string, len(oldMembers))
+	for _, member := range oldMembers {
+		oldRoles[member.Login] = member.Role
+	}
+
+	newRoles := make(map[string]string, len(newMembers))
+	for _, member := range newMembers {
+		newRoles[member.Login] = member.Role
+	}
+
+	m := MembershipDiff{Team: team}
+	for _, member := range newMembers {
+		oldRole, ok := oldRoles[member.Login]
+		switch {
+		case !ok:
+			m.Joined = append(m.Joined, member)
+		case oldRole != member.Role:
+			m.RoleChanges = append(m.RoleChanges, RoleChange{
+				Login:   member.Login,
+				OldRole: oldRole,
+				NewRole: member.Role,
+			})
+		}
+	}
+
+	for _, member := range oldMembers {
+		if _, ok := newRoles[member.Login]; !ok {
+			m.Left = append(m.Left, member)
+		}
+	}
+
+	if len(m.Joined) == 0 && len(m.Left) == 0 && len(m.RoleChanges) == 0 {
+		return nil
+	}
+
+	return &m
+}
+
+// overlay is a union of old and new organization graphs with diff statuses.
+type overlay struct {
+	Teams []overlayTeam
+	Edges []overlayEdge
+}
+
+type overlayTeam struct {
+	Slug    string
+	Name    string
+	Status  string
+	Members []overlayMember
+}
+
+type overlayMember struct {
+	Member
+	Status string
+}
+
+type overlayEdge struct {
+	From   string
+	To     string
+	Status string
+}
+
+// Overlay returns teams, members and parent relations of both organization states,
+// marked as added, removed or changed.
+func (d *Diff) Overlay() overlay {
+	var o overlay
+
+	for _, slug := range sortedKeys(d.oldOrg.Teams, d.newOrg.Teams) {
+		oldTeam, inOld := d.oldOrg.Teams[slug]
+		newTeam, inNew := d.newOrg.Teams[slug]
+
+		t := overlayTeam{Slug: slug}
+		switch {
+		case !inOld:
+			t.Status = statusAdded
+			oldTeam = &Team{}
+		case !inNew:
+			t.Status = statusRemoved
+			newTeam = &Team{}
+		}
+
+		t.Name = newTeam.Name
+		if !inNew {
+			t.Name = oldTeam.Name
+		}
+
+		oldRoles := make(map[string]string, len(oldTeam.Members))
+		for _, member := range oldTeam.Members {
+			oldRoles[member.Login] = member.Role
+		}
+
+		for _, member := range newTeam.Members {
+			oldRole, ok := oldRoles[member.Login]
+			switch {
+			case !ok:
+				t.Members = append(t.Members, overlayMember{member, statusAdded})
+			case oldRole != member.Role:
+				t.Members = append(t.Members, overlayMember{member, statusChanged})
+			default:
+				t.Members = append(t.Members, overlayMember{member, ""})
+			}
+			delete(oldRoles, member.Login)
+		}
+
+		for _, member := range oldTeam.Members {
+			if _, ok := oldRoles[member.Login]; ok {
+				t.Members = append(t.Members, overlayMember{member, statusRemoved})
+			}
+		}
+
+		sort.SliceStable(t.Members, func(i, j int) bool {
+			return strings.ToLower(t.Members[i].Login) < strings.ToLower(t.Members[j].Login)
+		})
+
+		o.Teams = append(o.Teams, t)
+
+		switch {
+		case oldTeam.Parent == newTeam.Parent:
+			if newTeam.Parent != "" {
+				o.Edges = append(o.Edges, overlayEdge{newTeam.Parent, slug, ""})
+			}
+		default:
+			if oldTeam.Parent != "" {
+				o.Edges = append(o.Edges, overlayEdge{oldTeam.Parent, slug, statusRemoved})
+			}
+			if newTeam.Parent != "" {
+				o.Edges = append(o.Edges, overlayEdge{newTeam.Parent, slug, statusAdded})
+			}
+		}
+	}
+
+	return o
+}
+
+// sortedKeys returns sorted union of teams slugs.
+func sortedKeys(teams ...map[string]*Team) []string {
+	set := map[string]struct{}{}
+	for _, t := range teams {
+		for slug := range t {
+			set[slug] = struct{}{}
+		}
+	}
+
+	keys := make([]string, 0, len(set))
+	for slug := range set {
+		keys = append(keys, slug)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func toSet(items []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+
+	return set
+}
+
+// setDifference returns sorted items of a that are not in b.
+func setDifference(a, b map[string]struct{}) []string {
+	var result []string
+	for item := range a {
+		if _, ok := b[item]; !ok {
+			result = append(result, item)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+type diffCommand struct {
+	Format   string `long:"format" description:"Output format" choice:"text" choice:"json" choice:"dot" default:"text"`
+	Template string `long:"template" description:"Go template, overrides format (optional)"`
+	Output   string `long:"output" description:"Output file, standard output if empty"`
+	Args     struct {
+		Old string `positional-arg-name:"OLD" description:"Old snapshot file"`
+		New string `positional-arg-name:"NEW" description:"New snapshot file"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *diffCommand) Execute(args []string) error {
+	oldSnapshot, err := readSnapshot(c.Args.Old)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot %q: %w", c.Args.Old, err)
+	}
+
+	newSnapshot, err := readSnapshot(c.Args.New)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot %q: %w", c.Args.New, err)
+	}
+
+	d, err := DiffSnapshots(oldSnapshot, newSnapshot)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c.Template != "":
+		err = renderTemplate(c.Template, "", c.Output, d)
+	case c.Format == "json":
+		err = writeOutput(c.Output, func(w io.Writer) error {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(d)
+		})
+	case c.Format == "dot":
+		err = renderTemplate("", builtinTemplate("diff.dot.tmpl"), c.Output, d)
+	default:
+		err = renderTemplate("", builtinTemplate("diff.txt.tmpl"), c.Output, d)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render diff: %w", err)
+	}
+
+	if c.Output != "" {
+		log.Println("Done!")
+	}
+
+	return nil
+}
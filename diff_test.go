@@ -0,0 +1,149 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestShouldDiffOrganizations(t *testing.T) {
+	oldTeams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-2"},
+		"test-team-2": {"test-user"},
+		"test-team-3": {"test-user-3"},
+	})
+	oldTeams["test-team-2"].Parent = "test-team"
+
+	newTeams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-4"},
+		"test-team-2": {"test-user"},
+		"test-team-4": {"test-user-3"},
+	})
+	newTeams["test-team"].Members[0].Role = RoleMaintainer
+	newTeams["test-team-4"].Parent = "test-team"
+
+	oldOrg := &Organization{
+		Teams:   oldTeams,
+		Members: []string{"test-user", "test-user-2", "test-user-3", "test-user-4"},
+	}
+	newOrg := &Organization{
+		Teams:   newTeams,
+		Members: []string{"test-user", "test-user-2", "test-user-3", "test-user-4"},
+	}
+
+	d := DiffOrganizations(oldOrg, newOrg)
+
+	if expected := []string{"test-team-4"}; !reflect.DeepEqual(d.AddedTeams, expected) {
+		t.Errorf("Expected added teams to be %v, got %v", expected, d.AddedTeams)
+	}
+
+	if expected := []string{"test-team-3"}; !reflect.DeepEqual(d.RemovedTeams, expected) {
+		t.Errorf("Expected removed teams to be %v, got %v", expected, d.RemovedTeams)
+	}
+
+	expectedReparented := []ReparentedTeam{{Team: "test-team-2", OldParent: "test-team", NewParent: ""}}
+	if !reflect.DeepEqual(d.ReparentedTeams, expectedReparented) {
+		t.Errorf("Expected re-parented teams to be %v, got %v", expectedReparented, d.ReparentedTeams)
+	}
+
+	expectedMemberships := []MembershipDiff{
+		{
+			Team:        "test-team",
+			Joined:      []Member{{Login: "test-user-4"}},
+			Left:        []Member{{Login: "test-user-2"}},
+			RoleChanges: []RoleChange{{Login: "test-user", OldRole: "", NewRole: RoleMaintainer}},
+		},
+		{
+			Team: "test-team-3",
+			Left: []Member{{Login: "test-user-3"}},
+		},
+		{
+			Team:   "test-team-4",
+			Joined: []Member{{Login: "test-user-3"}},
+		},
+	}
+	if !reflect.DeepEqual(d.Memberships, expectedMemberships) {
+		t.Errorf("Expected memberships to be %v, got %v", expectedMemberships, d.Memberships)
+	}
+
+	expectedWithoutTeam := WithoutTeamDiff{Entered: []string{"test-user-2"}, Left: []string{"test-user-4"}}
+	if !reflect.DeepEqual(d.WithoutTeam, expectedWithoutTeam) {
+		t.Errorf("Expected members without team diff to be %v, got %v", expectedWithoutTeam, d.WithoutTeam)
+	}
+
+	if d.Empty() {
+		t.Errorf("Expected diff not to be empty")
+	}
+
+	if !DiffOrganizations(oldOrg, oldOrg).Empty() {
+		t.Errorf("Expected diff of the same organization to be empty")
+	}
+}
+
+func TestShouldBuildDiffOverlay(t *testing.T) {
+	oldTeams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-2"},
+		"test-team-2": {"test-user"},
+	})
+	oldTeams["test-team-2"].Parent = "test-team"
+
+	newTeams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-3"},
+		"test-team-3": {"test-user"},
+	})
+	newTeams["test-team-3"].Parent = "test-team"
+
+	o := DiffOrganizations(&Organization{Teams: oldTeams}, &Organization{Teams: newTeams}).Overlay()
+
+	expectedTeams := []overlayTeam{
+		{
+			Slug: "test-team",
+			Name: "test-team",
+			Members: []overlayMember{
+				{Member{Login: "test-user"}, ""},
+				{Member{Login: "test-user-2"}, statusRemoved},
+				{Member{Login: "test-user-3"}, statusAdded},
+			},
+		},
+		{
+			Slug:    "test-team-2",
+			Name:    "test-team-2",
+			Status:  statusRemoved,
+			Members: []overlayMember{{Member{Login: "test-user"}, statusRemoved}},
+		},
+		{
+			Slug:    "test-team-3",
+			Name:    "test-team-3",
+			Status:  statusAdded,
+			Members: []overlayMember{{Member{Login: "test-user"}, statusAdded}},
+		},
+	}
+	if !reflect.DeepEqual(o.Teams, expectedTeams) {
+		t.Errorf("Expected overlay teams to be %v, got %v", expectedTeams, o.Teams)
+	}
+
+	expectedEdges := []overlayEdge{
+		{"test-team", "test-team-2", statusRemoved},
+		{"test-team", "test-team-3", statusAdded},
+	}
+	if !reflect.DeepEqual(o.Edges, expectedEdges) {
+		t.Errorf("Expected overlay edges to be %v, got %v", expectedEdges, o.Edges)
+	}
+}
+
+func TestShouldNotDiffSnapshotsWithHiddenMembers(t *testing.T) {
+	org := &Organization{
+		Teams:   newTestTeams(map[string][]string{"test-team": {"test-user"}}),
+		Members: []string{"test-user"},
+	}
+	hidden := newSnapshot(&Organization{Teams: newTestTeams(map[string][]string{"test-team": {}})}, time.Now(), time.Now())
+	hidden.HideMembers = true
+
+	if _, err := DiffSnapshots(newSnapshot(org, time.Now(), time.Now()), hidden); err == nil {
+		t.Error("Expected error comparing snapshots with and without members")
+	}
+
+	if _, err := DiffSnapshots(hidden, hidden); err != nil {
+		t.Errorf("Expected snapshots without members to be compared, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// format is a built-in output format.
+type format struct {
+	Template string                          // template file name in templates directory
+	Write    func(w io.Writer, d data) error // writes the output instead of template, if set
+	Ext      string                          // output file extension
+	Overlaps bool                            // output lists overlapping teams
+}
+
+// formats are built-in output formats by name.
+var formats = map[string]format{
+	"dot":       {Template: "dot.tmpl", Ext: ".dot"},
+	"mermaid":   {Template: "mermaid.tmpl", Ext: ".mmd"},
+	"plantuml":  {Template: "plantuml.tmpl", Ext: ".puml"},
+	"d2":        {Template: "d2.tmpl", Ext: ".d2"},
+	"markdown":  {Template: "markdown.tmpl", Ext: ".md"},
+	"report":    {Template: "report.tmpl", Ext: ".md", Overlaps: true},
+	"csv":       {Template: "csv.tmpl", Ext: ".csv"},
+	"json":      {Template: "json.tmpl", Ext: ".json", Overlaps: true},
+	"html":      {Template: "html.tmpl", Ext: ".html"},
+	"matrix":    {Write: writeMatrix, Ext: ".csv"},
+	"graphml":   {Write: writeGraphML, Ext: ".graphml"},
+	"gexf":      {Write: writeGEXF, Ext: ".gexf"},
+	"terraform": {Write: writeTerraform, Ext: ".tf"},
+	"yaml":      {Write: writeTeamsYAML, Ext: ".yaml"},
+	"backstage": {Write: writeBackstage, Ext: ".yaml"},
+	"svg":       {Write: writeSVG, Ext: ".svg"},
+}
+
+// dotLayouts are templates of dot format by layout name.
+var dotLayouts = map[string]string{
+	"records":  "dot.tmpl",          // teams with member logins inside
+	"members":  "dot-members.tmpl",  // members as separate nodes linked to their teams
+	"clusters": "dot-clusters.tmpl", // child teams inside boxes of their parents
+}
+
+// MemberTeams returns sorted slugs of teams each login is a direct member of,
+// for all logins of organization members and team members.
+// Members of child teams are not counted as members of parent teams.
+// Used by members layout of dot format.
+func (d data) MemberTeams() map[string][]string {
+	result := map[string][]string{}
+	for _, login := range allLogins(d) {
+		result[login] = []string{}
+	}
+
+	children := childTeams(d.Teams)
+	for _, slug := range sortedKeys(d.Teams) {
+		cells := teamCells(d.Teams, children, slug)
+		for _, member := range d.Teams[slug].Members {
+			if cells[member.Login] != cellInherited {
+				result[member.Login] = append(result[member.Login], slug)
+			}
+		}
+	}
+
+	return result
+}
+
+// render writes data to the output file in the format,
+// or using template file tmpl if it is set.
+func (f format) render(tmpl, output string, d data) error {
+	if tmpl == "" && f.Write != nil {
+		return writeOutput(output, func(w io.Writer) error {
+			return f.Write(w, d)
+		})
+	}
+
+	var text string
+	if tmpl == "" {
+		text = builtinTemplate(f.Template)
+	}
+
+	return renderTemplate(tmpl, text, output, d)
+}
+
+// builtinTemplate returns text of the embedded template.
+func builtinTemplate(name string) string {
+	b, err := templatesFS.ReadFile(path.Join("templates", name))
+	if err != nil {
+		panic(fmt.Sprintf("built-in template %q not found", name))
+	}
+
+	return string(b)
+}
+
+var funcMap = template.FuncMap{
+	"join": func(a []string, sep string) string {
+		return strings.Join(a, sep)
+	},
+	"replace": func(s, old, new string) string {
+		return strings.ReplaceAll(s, old, new)
+	},
+	"has": func(a []string, s string) bool {
+		for _, item := range a {
+			if item == s {
+				return true
+			}
+		}
+		return false
+	},
+	"nodeID":        nodeID,
+	"userNodeID":    userNodeID,
+	"dotText":       dotText,
+	"dotRecordText": dotRecordText,
+	"mermaidText":   mermaidText,
+	"markdownText":  markdownText,
+	"csvRow":        csvRow,
+	"toJSON":        toJSON,
+}
+
+// nodeID returns node ID for the team slug, safe to use in any diagram language.
+func nodeID(slug string) string {
+	return escapeID("team_", slug)
+}
+
+// userNodeID returns node ID for the user login, safe to use in any diagram language.
+func userNodeID(login string) string {
+	return escapeID("user_", login)
+}
+
+// escapeID returns s with prefix. Characters other than ASCII letters and digits
+// are replaced with their hex codes, so different strings never share the same ID.
+func escapeID(prefix, s string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, "_%x", r)
+		}
+	}
+
+	return b.String()
+}
+
+// dotText escapes characters that are not allowed in quoted Graphviz strings.
+func dotText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// dotRecordText escapes characters that are not allowed in Graphviz record labels,
+// which use braces, bars and angle brackets for fields and ports.
+func dotRecordText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"{", `\{`,
+		"}", `\}`,
+		"|", `\|`,
+		"<", `\<`,
+		">", `\>`,
+	).Replace(s)
+}
+
+// mermaidText escapes characters that are not allowed in Mermaid node labels.
+func mermaidText(s string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+	).Replace(s)
+}
+
+// markdownText escapes characters that break Markdown tables and formatting.
+func markdownText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"|", `\|`,
+		"*", `\*`,
+		"_", `\_`,
+		"<", "&lt;",
+		">", "&gt;",
+	).Replace(s)
+}
+
+// csvRow returns fields formatted as a CSV record, including the trailing newline.
+func csvRow(fields ...string) (string, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(fields); err != nil {
+		return "", err
+	}
+	w.Flush()
+
+	return b.String(), w.Error()
+}
+
+// toJSON returns v as indented JSON.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// MarshalJSON encodes subsets as sorted lists of team slugs.
+func (s subsets) MarshalJSON() ([]byte, error) {
+	result := make(map[string][]string, len(s))
+	for team := range s {
+		teamSubsets := s.GetSubsets(team)
+		sort.Strings(teamSubsets)
+		result[team] = teamSubsets
+	}
+
+	return json.Marshal(result)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestOrganization() *Organization {
+	teams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-2"},
+		"test-team-2": {"test-user"},
+		"test-team-3": {"test-user-2"},
+	})
+	teams["test-team"].Name = `Test "Team" | <1> & co`
+	teams["test-team"].Members[0].Role = RoleMaintainer
+	teams["test-team-2"].Parent = "test-team"
+
+	return &Organization{
+		ID:      123,
+		Login:   "test-org",
+		Teams:   teams,
+		Members: []string{"test-user", "test-user-2", "test-user-3"},
+	}
+}
+
+func TestShouldRenderAllFormats(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, f := range formats {
+		output := filepath.Join(dir, "graph"+f.Ext)
+		if err := f.render("", output, newData(newTestOrganization())); err != nil {
+			t.Errorf("Error rendering %s format: %v", name, err)
+			continue
+		}
+
+		b, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("Error reading output: %v", err)
+		}
+
+		if !strings.Contains(string(b), "test-user-3") {
+			t.Errorf("Expected %s output to contain members without team, got:\n%s", name, b)
+		}
+	}
+}
+
+func TestShouldRenderJSON(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "graph.json")
+	if err := renderTemplate("", builtinTemplate("json.tmpl"), output, newData(newTestOrganization())); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	var result struct {
+		Organization       Organization        `json:"organization"`
+		Subsets            map[string][]string `json:"subsets"`
+		MembersWithoutTeam []string            `json:"members_without_team"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		t.Fatalf("Error decoding output: %v\n%s", err, b)
+	}
+
+	if len(result.Organization.Teams) != 3 {
+		t.Errorf("Expected 3 teams, got %v", result.Organization.Teams)
+	}
+	if got := strings.Join(result.Subsets["test-team-2"], ","); got != "test-team" {
+		t.Errorf("Expected test-team-2 to be a subset of test-team, got %v", got)
+	}
+	if got := strings.Join(result.MembersWithoutTeam, ","); got != "test-user-3" {
+		t.Errorf("Expected test-user-3 to be without team, got %v", got)
+	}
+}
+
+func TestShouldEscapeTemplateText(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"nodeID", nodeID("team-1_a"), "team_team_2d1_5fa"},
+		{"mermaidText", mermaidText(`"a" <b>`), "#quot;a#quot; #lt;b#gt;"},
+		{"markdownText", markdownText("a|b *c*"), `a\|b \*c\*`},
+		{"dotRecordText", dotRecordText(`"a" | {b} <c>`), `\"a\" \| \{b\} \<c\>`},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("Expected %s to return %q, got %q", tt.name, tt.expected, tt.got)
+		}
+	}
+
+	row, err := csvRow("a,b", `c"d`, "e")
+	if err != nil {
+		t.Fatalf("Error formatting CSV row: %v", err)
+	}
+	if expected := "\"a,b\",\"c\"\"d\",e\n"; row != expected {
+		t.Errorf("Expected csvRow to return %q, got %q", expected, row)
+	}
+}
+
+func TestShouldEscapeDotRecordLabels(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "graph.dot")
+	if err := renderTemplate("", builtinTemplate("dot.tmpl"), output, newData(newTestOrganization())); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	expected := `"test-team" [ label="{*Test \"Team\" \| \<1\> & co*|★ test-user|test-user-2}" ]`
+	if !strings.Contains(string(b), expected) {
+		t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+	}
+}
+
+func TestShouldEmbedDataInHTML(t *testing.T) {
+	org := newTestOrganization()
+	org.Teams["test-team-3"].Name = "</script><script>alert(1)</script>"
+
+	output := filepath.Join(t.TempDir(), "graph.html")
+	if err := renderTemplate("", builtinTemplate("html.tmpl"), output, newData(org)); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	if strings.Contains(string(b), "alert(1)</script>") {
+		t.Errorf("Expected team name to be escaped, got:\n%s", b)
+	}
+	if strings.Contains(string(b), "<script src=") {
+		t.Errorf("Expected no external scripts, got:\n%s", b)
+	}
+}
+
+func TestShouldFindMemberTeams(t *testing.T) {
+	org := newTestOrganization()
+	org.Teams["test-team-3"].Parent = "test-team-2"
+
+	expected := map[string][]string{
+		"test-user":   {"test-team", "test-team-2"},
+		"test-user-2": {"test-team-3"},
+		"test-user-3": {},
+	}
+	if got := newData(org).MemberTeams(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected member teams to be %v, got %v", expected, got)
+	}
+}
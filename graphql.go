@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+const graphqlURL = "https://api.github.com/graphql"
+
+type graphqlService interface {
+	Query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error
+}
+
+// GraphQLClient is a minimal GitHub GraphQL API client.
+type GraphQLClient struct {
+	HTTPClient *http.Client
+	URL        string
+}
+
+type graphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphqlError  `json:"errors"`
+}
+
+type graphqlError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type graphqlErrors []graphqlError
+
+func (e graphqlErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Query sends query with variables and decodes response data into result.
+func (c *GraphQLClient) Query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	url := c.URL
+	if url == "" {
+		url = graphqlURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// GraphQL API reports HTTP errors the same way as REST API does
+	if err := github.CheckResponse(resp); err != nil {
+		return err
+	}
+
+	var response graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(response.Errors) > 0 {
+		for _, e := range response.Errors {
+			// primary rate limit is reported with 200 OK status
+			if e.Type == "RATE_LIMITED" {
+				return &github.RateLimitError{
+					Rate:     parseRate(resp),
+					Response: resp,
+					Message:  e.Message,
+				}
+			}
+		}
+
+		return graphqlErrors(response.Errors)
+	}
+
+	return json.Unmarshal(response.Data, result)
+}
+
+// graphqlRateLimit is the rateLimit field of GraphQL API response data.
+type graphqlRateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// graphqlData decodes response data into result,
+// keeping the rate limit if the query asked for it.
+type graphqlData struct {
+	result    interface{}
+	rateLimit *graphqlRateLimit
+}
+
+func (d *graphqlData) UnmarshalJSON(b []byte) error {
+	var data struct {
+		RateLimit *graphqlRateLimit `json:"rateLimit"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	d.rateLimit = data.RateLimit
+
+	return json.Unmarshal(b, d.result)
+}
+
+// response returns rate limit of the query the same way REST API calls report it,
+// or nil if the query did not ask for it.
+func (d *graphqlData) response() *github.Response {
+	if d.rateLimit == nil {
+		return nil
+	}
+
+	return &github.Response{Rate: github.Rate{
+		Limit:     d.rateLimit.Limit,
+		Remaining: d.rateLimit.Remaining,
+		Reset:     github.Timestamp{Time: d.rateLimit.ResetAt},
+	}}
+}
+
+type graphqlPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphqlMembers struct {
+	PageInfo graphqlPageInfo `json:"pageInfo"`
+	Edges    []struct {
+		Role string `json:"role"`
+		Node struct {
+			Login string `json:"login"`
+		} `json:"node"`
+	} `json:"edges"`
+}
+
+type graphqlTeam struct {
+	DatabaseID  int64  `json:"databaseId"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Privacy     string `json:"privacy"`
+	ParentTeam  *struct {
+		Slug string `json:"slug"`
+	} `json:"parentTeam"`
+	Members graphqlMembers `json:"members"`
+}
+
+// graphqlPrivacy maps GraphQL API team privacy to the REST API one.
+var graphqlPrivacy = map[string]string{
+	"SECRET":  "secret",
+	"VISIBLE": "closed",
+}
+
+const teamsQuery = `query($org: String!, $cursor: String, $withMembers: Boolean!) {
+  rateLimit { limit remaining resetAt }
+  organization(login: $org) {
+    teams(first: 100, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        databaseId
+        name
+        slug
+        description
+        privacy
+        parentTeam { slug }
+        members(first: 100) @include(if: $withMembers) {
+          pageInfo { hasNextPage endCursor }
+          edges { role node { login } }
+        }
+      }
+    }
+  }
+}`
+
+const teamMembersQuery = `query($org: String!, $slug: String!, $cursor: String) {
+  rateLimit { limit remaining resetAt }
+  organization(login: $org) {
+    team(slug: $slug) {
+      members(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        edges { role node { login } }
+      }
+    }
+  }
+}`
+
+// teamsGraphQL returns the same data as Teams does, but fetches teams
+// together with their members in batches, instead of a request per team.
+func (p *Processor) teamsGraphQL(orgName string) (map[string]*Team, error) {
+	teams := make(map[string]*Team)
+
+	var cursor *string
+	for {
+		var result struct {
+			Organization *struct {
+				Teams struct {
+					PageInfo graphqlPageInfo `json:"pageInfo"`
+					Nodes    []graphqlTeam   `json:"nodes"`
+				} `json:"teams"`
+			} `json:"organization"`
+		}
+
+		err := p.GraphQLService.Query(
+			p.Context,
+			teamsQuery,
+			map[string]interface{}{
+				"org":         orgName,
+				"cursor":      cursor,
+				"withMembers": !p.HideMembers,
+			},
+			&result,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams: %w", err)
+		}
+		if result.Organization == nil {
+			return nil, fmt.Errorf("organization %q not found", orgName)
+		}
+
+		for _, team := range result.Organization.Teams.Nodes {
+			t := &Team{
+				ID:          team.DatabaseID,
+				Slug:        team.Slug,
+				Name:        team.Name,
+				Description: team.Description,
+				Privacy:     graphqlPrivacy[team.Privacy],
+			}
+
+			if !p.HideMembers {
+				members, err := p.getTeamMembersGraphQL(orgName, team)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list team members for %q: %w", team.Name, err)
+				}
+
+				t.Members = members
+			}
+
+			if team.ParentTeam != nil {
+				t.Parent = team.ParentTeam.Slug
+			}
+
+			teams[t.Slug] = t
+		}
+
+		pageInfo := result.Organization.Teams.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+
+		cursor = &pageInfo.EndCursor
+	}
+
+	return teams, nil
+}
+
+// getTeamMembersGraphQL returns team members,
+// fetching the pages that did not fit into the teams query.
+func (p *Processor) getTeamMembersGraphQL(orgName string, team graphqlTeam) ([]Member, error) {
+	var result []Member
+
+	members := team.Members
+	for {
+		for _, edge := range members.Edges {
+			result = append(result, Member{Login: edge.Node.Login, Role: strings.ToLower(edge.Role)})
+		}
+
+		if !members.PageInfo.HasNextPage {
+			break
+		}
+
+		var response struct {
+			Organization *struct {
+				Team *struct {
+					Members graphqlMembers `json:"members"`
+				} `json:"team"`
+			} `json:"organization"`
+		}
+
+		err := p.GraphQLService.Query(
+			p.Context,
+			teamMembersQuery,
+			map[string]interface{}{
+				"org":    orgName,
+				"slug":   team.Slug,
+				"cursor": members.PageInfo.EndCursor,
+			},
+			&response,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if response.Organization == nil || response.Organization.Team == nil {
+			return nil, fmt.Errorf("team %q not found", team.Slug)
+		}
+
+		members = response.Organization.Team.Members
+	}
+
+	sortMembers(result)
+
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockGraphQLService struct {
+	mock.Mock
+}
+
+func (m *mockGraphQLService) Query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	args := m.Called(ctx, query, variables)
+	if err := args.Error(1); err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(args.String(0)), result)
+}
+
+func TestShouldGetTeamsGraphQL(t *testing.T) {
+	mockGS := new(mockGraphQLService)
+	mockGS.
+		On("Query", mock.Anything, teamsQuery, map[string]interface{}{"org": "test-org", "cursor": (*string)(nil), "withMembers": true}).
+		Return(`{"organization": {"teams": {
+			"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+			"nodes": [
+				{"databaseId": 1, "name": "Test Team", "slug": "test-team", "privacy": "VISIBLE",
+				 "members": {"pageInfo": {"hasNextPage": true, "endCursor": "m1"}, "edges": [{"role": "MAINTAINER", "node": {"login": "test-user-2"}}]}}
+			]
+		}}}`, nil)
+	cursor := "c1"
+	mockGS.
+		On("Query", mock.Anything, teamsQuery, map[string]interface{}{"org": "test-org", "cursor": &cursor, "withMembers": true}).
+		Return(`{"organization": {"teams": {
+			"pageInfo": {"hasNextPage": false, "endCursor": "c2"},
+			"nodes": [
+				{"databaseId": 2, "name": "Test Team 2", "slug": "test-team-2", "description": "Second team", "privacy": "SECRET", "parentTeam": {"slug": "test-team"},
+				 "members": {"pageInfo": {"hasNextPage": false}, "edges": [{"role": "MEMBER", "node": {"login": "test-user-4"}}, {"role": "MEMBER", "node": {"login": "test-user-3"}}]}}
+			]
+		}}}`, nil)
+	mockGS.
+		On("Query", mock.Anything, teamMembersQuery, map[string]interface{}{"org": "test-org", "slug": "test-team", "cursor": "m1"}).
+		Return(`{"organization": {"team": {"members": {
+			"pageInfo": {"hasNextPage": false},
+			"edges": [{"role": "MEMBER", "node": {"login": "test-user"}}]
+		}}}}`, nil)
+
+	processor := Processor{
+		Context:        context.Background(),
+		GraphQLService: mockGS,
+	}
+
+	teams, err := processor.Teams("test-org", 123)
+	if err != nil {
+		t.Errorf("Error getting teams: %v", err)
+	}
+
+	expected := map[string]*Team{
+		"test-team": {
+			ID:      1,
+			Slug:    "test-team",
+			Name:    "Test Team",
+			Privacy: "closed",
+			Members: []Member{{Login: "test-user", Role: "member"}, {Login: "test-user-2", Role: "maintainer"}},
+		},
+		"test-team-2": {
+			ID:          2,
+			Slug:        "test-team-2",
+			Name:        "Test Team 2",
+			Description: "Second team",
+			Privacy:     "secret",
+			Parent:      "test-team",
+			Members:     []Member{{Login: "test-user-3", Role: "member"}, {Login: "test-user-4", Role: "member"}},
+		},
+	}
+
+	if !reflect.DeepEqual(teams, expected) {
+		t.Errorf("Expected teams to be %v, got %v", expected, teams)
+	}
+}
+
+func TestShouldFailGetTeamsGraphQL(t *testing.T) {
+	mockGS := new(mockGraphQLService)
+	mockGS.On("Query", mock.Anything, teamsQuery, mock.Anything).Return("", fmt.Errorf("error"))
+
+	processor := Processor{
+		Context:        context.Background(),
+		GraphQLService: mockGS,
+	}
+
+	if _, err := processor.Teams("test-org", 123); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestShouldQueryGraphQL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var req graphqlRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("Error decoding request: %v", err)
+		}
+
+		switch req.Variables["org"] {
+		case "test-org":
+			fmt.Fprint(w, `{"data": {"organization": {"login": "test-org"}}}`)
+		case "bad-org":
+			fmt.Fprint(w, `{"data": {"organization": null}, "errors": [{"type": "NOT_FOUND", "message": "not found"}]}`)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client := GraphQLClient{HTTPClient: server.Client(), URL: server.URL}
+
+	var result struct {
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+	}
+
+	err := client.Query(context.Background(), "query", map[string]interface{}{"org": "test-org"}, &result)
+	if err != nil {
+		t.Errorf("Error querying: %v", err)
+	}
+	if result.Organization.Login != "test-org" {
+		t.Errorf("Expected test-org, got %v", result.Organization.Login)
+	}
+
+	err = client.Query(context.Background(), "query", map[string]interface{}{"org": "bad-org"}, &result)
+	if err == nil || err.Error() != "not found" {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	err = client.Query(context.Background(), "query", map[string]interface{}{"org": "broken-org"}, &result)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
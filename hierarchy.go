@@ -0,0 +1,104 @@
+package main
+
+import "fmt"
+
+// Kinds of hierarchy findings.
+const (
+	hierarchySubsetNotParent = "subset-not-parent" // team is a subset of a team that is not its ancestor
+	hierarchySameMembers     = "same-members"      // teams with identical members are not nested
+)
+
+// hierarchyFinding is a mismatch between team parents and team members,
+// with a suggested parent for the team.
+type hierarchyFinding struct {
+	Kind            string `json:"kind"`
+	Team            string `json:"team"`
+	Parent          string `json:"parent,omitempty"`   // current parent team slug
+	Superset        string `json:"superset,omitempty"` // team that has all members of the team
+	SuggestedParent string `json:"suggested_parent"`   // empty for top level
+	Suggestion      string `json:"suggestion"`
+}
+
+// HierarchyFindings returns teams whose members do not match their place in the hierarchy:
+// subset relations of teams not reflected by the hierarchy, and not nested teams with identical members.
+//
+// GitHub lists members of child teams as members of the parent team,
+// so every team is a subset of its ancestors. A subset relation is reflected
+// when the superset is an ancestor of the team, or has all members of one of them.
+func (d data) HierarchyFindings() []hierarchyFinding {
+	var findings []hierarchyFinding
+
+	for _, slug := range sortedKeys(d.Teams) {
+		ancestors := d.ancestors(slug)
+		for _, superset := range d.SubsetOf(slug) {
+			if d.Subsets.IsSubset(superset, slug) {
+				continue // identical members are reported below
+			}
+			if d.hasAncestorWithin(ancestors, superset) {
+				continue
+			}
+			if _, ok := d.ancestors(superset)[slug]; ok {
+				continue // nested the other way, moving would make a cycle
+			}
+
+			findings = append(findings, hierarchyFinding{
+				Kind:            hierarchySubsetNotParent,
+				Team:            slug,
+				Parent:          d.Teams[slug].Parent,
+				Superset:        superset,
+				SuggestedParent: superset,
+				Suggestion:      fmt.Sprintf("move %s under %s", slug, superset),
+			})
+		}
+	}
+
+	for _, g := range d.SubsetGroups {
+		for _, slug := range g[1:] {
+			if _, ok := d.ancestors(slug)[g[0]]; ok {
+				continue
+			}
+			if _, ok := d.ancestors(g[0])[slug]; ok {
+				continue
+			}
+
+			findings = append(findings, hierarchyFinding{
+				Kind:            hierarchySameMembers,
+				Team:            slug,
+				Parent:          d.Teams[slug].Parent,
+				Superset:        g[0],
+				SuggestedParent: g[0],
+				Suggestion:      fmt.Sprintf("merge %s into %s, or move %s under %s", slug, g[0], slug, g[0]),
+			})
+		}
+	}
+
+	return findings
+}
+
+// ancestors returns slugs of the parent team of the team, its parent and so on.
+func (d data) ancestors(slug string) map[string]struct{} {
+	result := map[string]struct{}{}
+	for parent := d.Teams[slug].Parent; ; parent = d.Teams[parent].Parent {
+		if _, ok := d.Teams[parent]; !ok {
+			break
+		}
+		if _, ok := result[parent]; ok || parent == slug {
+			break // cycle
+		}
+		result[parent] = struct{}{}
+	}
+
+	return result
+}
+
+// hasAncestorWithin reports whether one of ancestors is the superset team
+// or has no members outside of it.
+func (d data) hasAncestorWithin(ancestors map[string]struct{}, superset string) bool {
+	for ancestor := range ancestors {
+		if ancestor == superset || d.Subsets.IsSubset(ancestor, superset) {
+			return true
+		}
+	}
+
+	return false
+}
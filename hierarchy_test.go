@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShouldFindHierarchyFindings(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"eng":      {"test-user", "test-user-2", "test-user-3"},
+		"backend":  {"test-user", "test-user-3"},
+		"frontend": {"test-user-2"},
+		"db":       {"test-user-3"},
+		"platform": {"test-user", "test-user-3", "test-user-4"},
+		"ops":      {"test-user-4"},
+		"ops-copy": {"test-user-4"},
+	})
+	teams["backend"].Parent = "eng"
+	teams["frontend"].Parent = "eng"
+	teams["db"].Parent = "backend"
+	teams["ops"].Parent = "platform"
+
+	expected := []hierarchyFinding{
+		{
+			Kind:            hierarchySubsetNotParent,
+			Team:            "backend",
+			Parent:          "eng",
+			Superset:        "platform",
+			SuggestedParent: "platform",
+			Suggestion:      "move backend under platform",
+		},
+		{
+			Kind:            hierarchySubsetNotParent,
+			Team:            "ops-copy",
+			Superset:        "platform",
+			SuggestedParent: "platform",
+			Suggestion:      "move ops-copy under platform",
+		},
+		{
+			Kind:            hierarchySameMembers,
+			Team:            "ops-copy",
+			Superset:        "ops",
+			SuggestedParent: "ops",
+			Suggestion:      "merge ops-copy into ops, or move ops-copy under ops",
+		},
+	}
+
+	if got := newData(&Organization{Teams: teams}).HierarchyFindings(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected hierarchy findings to be %+v, got %+v", expected, got)
+	}
+}
+
+func TestShouldSuggestSiblingSuperset(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"eng":     {"test-user", "test-user-2", "test-user-3"},
+		"backend": {"test-user", "test-user-2"},
+		"api":     {"test-user"},
+	})
+	teams["backend"].Parent = "eng"
+	teams["api"].Parent = "eng"
+
+	expected := []hierarchyFinding{{
+		Kind:            hierarchySubsetNotParent,
+		Team:            "api",
+		Parent:          "eng",
+		Superset:        "backend",
+		SuggestedParent: "backend",
+		Suggestion:      "move api under backend",
+	}}
+	if got := newData(&Organization{Teams: teams}).HierarchyFindings(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected hierarchy findings to be %+v, got %+v", expected, got)
+	}
+}
+
+func TestShouldNotReportConsistentHierarchy(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"eng":     {"test-user", "test-user-2", "test-user-3"},
+		"backend": {"test-user", "test-user-3"},
+		"db":      {"test-user"},
+		"web":     {"test-user-2"},
+	})
+	teams["backend"].Parent = "eng"
+	teams["db"].Parent = "backend"
+	teams["web"].Parent = "eng"
+
+	if findings := newData(&Organization{Teams: teams}).HierarchyFindings(); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", findings)
+	}
+}
+
+func TestShouldRenderHierarchyFindings(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"eng":      {"test-user", "test-user-2"},
+		"backend":  {"test-user"},
+		"platform": {"test-user", "test-user-3"},
+		"ops":      {"test-user-3"},
+		"ops-copy": {"test-user-3"},
+	})
+	teams["backend"].Parent = "eng"
+	teams["ops"].Parent = "platform"
+	teams["ops-copy"].Parent = "platform"
+
+	output := filepath.Join(t.TempDir(), "report.md")
+	if err := renderTemplate("", builtinTemplate("report.tmpl"), output, newData(&Organization{Login: "test-org", Teams: teams})); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	for _, expected := range []string{
+		"| [backend](#backend) | [eng](#eng) | all members are in [platform](#platform) | move backend under platform |",
+		"| [ops-copy](#ops-copy) | [platform](#platform) | same members as [ops](#ops) | merge ops-copy into ops, or move ops-copy under ops |",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
+
+func TestShouldRenderHierarchyWithMissingParent(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"x": {"test-user"},
+		"y": {"test-user", "test-user-2"},
+	})
+	teams["x"].Parent = "ghost"
+
+	output := filepath.Join(t.TempDir(), "report.md")
+	if err := renderTemplate("", builtinTemplate("report.tmpl"), output, newData(&Organization{Login: "test-org", Teams: teams})); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	expected := "| [x](#x) | ghost | all members are in [y](#y) | move x under y |"
+	if !strings.Contains(string(b), expected) {
+		t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+	}
+}
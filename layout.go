@@ -0,0 +1,345 @@
+package main
+
+import "sort"
+
+// Layered layout spacing in pixels.
+const (
+	layoutMargin     = 20.0
+	layoutNodeGap    = 30.0 // between nodes of a layer
+	layoutLayerGap   = 60.0 // between layers
+	layoutDummyWidth = 10.0 // width reserved for edges crossing a layer
+	layoutSweeps     = 12   // iterations of crossing reduction and positioning
+)
+
+// layoutNode is a node of a layered graph.
+type layoutNode struct {
+	Width  float64
+	Height float64
+	X      float64 // left side, set by layout
+	Y      float64 // top side, set by layout
+	Layer  int     // set by layout
+
+	pos float64 // position in layer used for ordering
+}
+
+// layoutEdge is a directed edge between nodes by their index.
+// Nodes are appended to the layout for edges spanning several layers.
+type layoutEdge struct {
+	From   int
+	To     int
+	Points []layoutPoint // route from From to To, set by layout
+
+	chain []int // nodes from the upper to the lower end of the edge, including dummy nodes
+}
+
+type layoutPoint struct {
+	X, Y float64
+}
+
+// layeredLayout places nodes of a directed graph in horizontal layers,
+// so edges point downwards, in the spirit of Sugiyama et al.:
+// cycles are broken, nodes are assigned to layers by longest path,
+// long edges are split with dummy nodes, crossings are reduced with barycenter heuristic,
+// and nodes are moved towards their neighbors without overlapping.
+type layeredLayout struct {
+	Nodes  []*layoutNode
+	Edges  []*layoutEdge
+	Width  float64 // set by layout
+	Height float64 // set by layout
+
+	layers [][]int // node indexes by layer, in order
+	up     [][]int // neighbors in the previous layer by node index
+	down   [][]int // neighbors in the next layer by node index
+}
+
+// Run sets positions of nodes and routes of edges.
+func (l *layeredLayout) Run() {
+	l.assignLayers()
+	l.addDummyNodes()
+	l.orderLayers()
+	l.assignCoordinates()
+	l.routeEdges()
+}
+
+// assignLayers puts every node one layer below its lowest predecessor,
+// ignoring edges that close cycles.
+func (l *layeredLayout) assignLayers() {
+	n := len(l.Nodes)
+	out := make([][]*layoutEdge, n)
+	for _, e := range l.Edges {
+		if e.From != e.To {
+			out[e.From] = append(out[e.From], e)
+		}
+	}
+
+	// depth-first search marks edges to nodes on the stack as reversed
+	reversed := map[*layoutEdge]bool{}
+	state := make([]int, n) // 0 – not visited, 1 – on stack, 2 – done
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = 1
+		for _, e := range out[v] {
+			switch state[e.To] {
+			case 0:
+				visit(e.To)
+			case 1:
+				reversed[e] = true
+			}
+		}
+		state[v] = 2
+	}
+	for v := range l.Nodes {
+		if state[v] == 0 {
+			visit(v)
+		}
+	}
+
+	succ := make([][]int, n)
+	inDegree := make([]int, n)
+	for _, e := range l.Edges {
+		if e.From == e.To {
+			continue
+		}
+		from, to := e.From, e.To
+		if reversed[e] {
+			from, to = to, from
+		}
+		e.chain = []int{from, to}
+		succ[from] = append(succ[from], to)
+		inDegree[to]++
+	}
+
+	var queue []int
+	for v := range l.Nodes {
+		if inDegree[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range succ[v] {
+			if l.Nodes[v].Layer+1 > l.Nodes[w].Layer {
+				l.Nodes[w].Layer = l.Nodes[v].Layer + 1
+			}
+			inDegree[w]--
+			if inDegree[w] == 0 {
+				queue = append(queue, w)
+			}
+		}
+	}
+}
+
+// addDummyNodes splits edges spanning several layers with dummy nodes,
+// so every edge connects adjacent layers.
+func (l *layeredLayout) addDummyNodes() {
+	for _, e := range l.Edges {
+		if e.chain == nil {
+			continue
+		}
+
+		from, to := e.chain[0], e.chain[1]
+		chain := []int{from}
+		for layer := l.Nodes[from].Layer + 1; layer < l.Nodes[to].Layer; layer++ {
+			l.Nodes = append(l.Nodes, &layoutNode{Width: layoutDummyWidth, Layer: layer})
+			chain = append(chain, len(l.Nodes)-1)
+		}
+		e.chain = append(chain, to)
+	}
+
+	l.up = make([][]int, len(l.Nodes))
+	l.down = make([][]int, len(l.Nodes))
+	for _, e := range l.Edges {
+		for i := 1; i < len(e.chain); i++ {
+			l.down[e.chain[i-1]] = append(l.down[e.chain[i-1]], e.chain[i])
+			l.up[e.chain[i]] = append(l.up[e.chain[i]], e.chain[i-1])
+		}
+	}
+
+	for v, node := range l.Nodes {
+		for len(l.layers) <= node.Layer {
+			l.layers = append(l.layers, nil)
+		}
+		node.pos = float64(len(l.layers[node.Layer]))
+		l.layers[node.Layer] = append(l.layers[node.Layer], v)
+	}
+}
+
+// orderLayers reduces edge crossings by sorting nodes of each layer
+// by average position of their neighbors, sweeping down and up.
+func (l *layeredLayout) orderLayers() {
+	for i := 0; i < layoutSweeps; i++ {
+		if i%2 == 0 {
+			for layer := 1; layer < len(l.layers); layer++ {
+				l.sortLayer(layer, l.up)
+			}
+		} else {
+			for layer := len(l.layers) - 2; layer >= 0; layer-- {
+				l.sortLayer(layer, l.down)
+			}
+		}
+	}
+}
+
+func (l *layeredLayout) sortLayer(layer int, neighbors [][]int) {
+	nodes := l.layers[layer]
+
+	barycenters := make(map[int]float64, len(nodes))
+	for _, v := range nodes {
+		barycenters[v] = l.Nodes[v].pos
+		if len(neighbors[v]) == 0 {
+			continue
+		}
+
+		sum := 0.0
+		for _, w := range neighbors[v] {
+			sum += l.Nodes[w].pos
+		}
+		barycenters[v] = sum / float64(len(neighbors[v]))
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return barycenters[nodes[i]] < barycenters[nodes[j]]
+	})
+	for i, v := range nodes {
+		l.Nodes[v].pos = float64(i)
+	}
+}
+
+// assignCoordinates places layers from top to bottom,
+// and moves nodes towards their neighbors keeping the order within layers.
+func (l *layeredLayout) assignCoordinates() {
+	y := layoutMargin
+	for _, nodes := range l.layers {
+		height := 0.0
+		x := 0.0
+		for _, v := range nodes {
+			node := l.Nodes[v]
+			node.Y = y
+			node.X = x
+			x += node.Width + layoutNodeGap
+			if node.Height > height {
+				height = node.Height
+			}
+		}
+		y += height + layoutLayerGap
+	}
+
+	for i := 0; i < layoutSweeps; i++ {
+		if i%2 == 0 {
+			for layer := 1; layer < len(l.layers); layer++ {
+				l.alignLayer(layer, l.up)
+			}
+		} else {
+			for layer := len(l.layers) - 2; layer >= 0; layer-- {
+				l.alignLayer(layer, l.down)
+			}
+		}
+	}
+
+	minX := 0.0
+	for i, node := range l.Nodes {
+		if i == 0 || node.X < minX {
+			minX = node.X
+		}
+	}
+
+	l.Width, l.Height = 0, 0
+	for _, node := range l.Nodes {
+		node.X += layoutMargin - minX
+		if node.X+node.Width+layoutMargin > l.Width {
+			l.Width = node.X + node.Width + layoutMargin
+		}
+		if node.Y+node.Height+layoutMargin > l.Height {
+			l.Height = node.Y + node.Height + layoutMargin
+		}
+	}
+}
+
+// alignLayer moves nodes of the layer to the average center of their neighbors.
+// Overlaps are resolved by pushing nodes right and, separately, left,
+// and taking the average of both placements.
+func (l *layeredLayout) alignLayer(layer int, neighbors [][]int) {
+	nodes := l.layers[layer]
+	if len(nodes) == 0 {
+		return
+	}
+
+	desired := make([]float64, len(nodes)) // centers
+	for i, v := range nodes {
+		node := l.Nodes[v]
+		desired[i] = node.X + node.Width/2
+		if len(neighbors[v]) == 0 {
+			continue
+		}
+
+		sum := 0.0
+		for _, w := range neighbors[v] {
+			sum += l.Nodes[w].X + l.Nodes[w].Width/2
+		}
+		desired[i] = sum / float64(len(neighbors[v]))
+	}
+
+	separation := func(i int) float64 { // between centers of nodes i-1 and i
+		return l.Nodes[nodes[i-1]].Width/2 + layoutNodeGap + l.Nodes[nodes[i]].Width/2
+	}
+
+	right := make([]float64, len(nodes))
+	for i := range nodes {
+		right[i] = desired[i]
+		if i > 0 && right[i] < right[i-1]+separation(i) {
+			right[i] = right[i-1] + separation(i)
+		}
+	}
+
+	left := make([]float64, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		left[i] = desired[i]
+		if i < len(nodes)-1 && left[i] > left[i+1]-separation(i+1) {
+			left[i] = left[i+1] - separation(i+1)
+		}
+	}
+
+	for i, v := range nodes {
+		l.Nodes[v].X = (left[i]+right[i])/2 - l.Nodes[v].Width/2
+	}
+}
+
+// routeEdges sets edge points from the bottom of the upper node
+// through dummy nodes to the top of the lower node.
+func (l *layeredLayout) routeEdges() {
+	heights := make([]float64, len(l.layers))
+	for layer, nodes := range l.layers {
+		for _, v := range nodes {
+			if l.Nodes[v].Height > heights[layer] {
+				heights[layer] = l.Nodes[v].Height
+			}
+		}
+	}
+
+	for _, e := range l.Edges {
+		e.Points = nil
+		if e.chain == nil {
+			continue
+		}
+
+		for i, v := range e.chain {
+			node := l.Nodes[v]
+			x := node.X + node.Width/2
+			switch {
+			case i == 0:
+				e.Points = append(e.Points, layoutPoint{x, node.Y + node.Height})
+			case i == len(e.chain)-1:
+				e.Points = append(e.Points, layoutPoint{x, node.Y})
+			default:
+				e.Points = append(e.Points, layoutPoint{x, node.Y}, layoutPoint{x, node.Y + heights[node.Layer]})
+			}
+		}
+
+		if e.chain[0] != e.From {
+			for i, j := 0, len(e.Points)-1; i < j; i, j = i+1, j-1 {
+				e.Points[i], e.Points[j] = e.Points[j], e.Points[i]
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestShouldLayoutLayers(t *testing.T) {
+	// 0 -> 1 -> 2, 0 -> 2, 3 -> 2, 2 -> 0 closes a cycle, 4 is isolated
+	l := &layeredLayout{}
+	for i := 0; i < 5; i++ {
+		l.Nodes = append(l.Nodes, &layoutNode{Width: 50, Height: 20})
+	}
+	l.Edges = []*layoutEdge{{From: 0, To: 1}, {From: 1, To: 2}, {From: 0, To: 2}, {From: 3, To: 2}, {From: 2, To: 0}}
+	l.Run()
+
+	for i, expected := range []int{0, 1, 2, 0, 0} {
+		if l.Nodes[i].Layer != expected {
+			t.Errorf("Expected node %d to be in layer %d, got %d", i, expected, l.Nodes[i].Layer)
+		}
+	}
+
+	if len(l.Nodes) != 8 {
+		t.Errorf("Expected 3 dummy nodes for edges 0 -> 2, 3 -> 2 and 2 -> 0, got %d nodes", len(l.Nodes)-5)
+	}
+
+	// nodes of the same layer do not overlap
+	for i, a := range l.Nodes {
+		for j, b := range l.Nodes {
+			if i < j && a.Layer == b.Layer && a.X < b.X+b.Width && b.X < a.X+a.Width {
+				t.Errorf("Expected nodes %d and %d not to overlap: %+v, %+v", i, j, a, b)
+			}
+		}
+		if a.X < 0 || a.Y < 0 || a.X+a.Width > l.Width || a.Y+a.Height > l.Height {
+			t.Errorf("Expected node %d to be inside %vx%v, got %+v", i, l.Width, l.Height, a)
+		}
+	}
+
+	for _, e := range l.Edges {
+		from, to := l.Nodes[e.From], l.Nodes[e.To]
+		first, last := e.Points[0], e.Points[len(e.Points)-1]
+		if first.X != from.X+from.Width/2 || last.X != to.X+to.Width/2 {
+			t.Errorf("Expected edge %d -> %d to connect node centers, got %v", e.From, e.To, e.Points)
+		}
+		if (to.Layer > from.Layer) != (last.Y > first.Y) {
+			t.Errorf("Expected edge %d -> %d to go from layer %d to layer %d, got %v", e.From, e.To, from.Layer, to.Layer, e.Points)
+		}
+	}
+
+	if n := len(l.Edges[2].Points); n != 4 {
+		t.Errorf("Expected edge 0 -> 2 to bend in layer 1, got %d points", n)
+	}
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+)
+
+// Lint severities, in SARIF levels, from the lowest.
+var lintSeverities = []string{"note", "warning", "error"}
+
+// lintFinding is a problem found by a lint rule.
+type lintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Team     string `json:"team,omitempty"`  // team slug
+	Login    string `json:"login,omitempty"` // member login
+	Message  string `json:"message"`
+}
+
+// lintRule checks organization data.
+type lintRule struct {
+	ID          string
+	Description string
+	Severity    string // default severity
+	Check       func(d data, c *lintCommand) []lintFinding
+}
+
+// lintRules are rules of lint command, in order of output.
+var lintRules = []lintRule{
+	{
+		ID:          "empty-team",
+		Description: "Team has no members",
+		Severity:    "warning",
+		Check: func(d data, c *lintCommand) []lintFinding {
+			var findings []lintFinding
+			for _, slug := range d.EmptyTeams() {
+				findings = append(findings, lintFinding{Team: slug, Message: fmt.Sprintf("team %s has no members", slug)})
+			}
+			return findings
+		},
+	},
+	{
+		ID:          "single-member-team",
+		Description: "Team has a single member",
+		Severity:    "note",
+		Check: func(d data, c *lintCommand) []lintFinding {
+			var findings []lintFinding
+			for _, slug := range sortedKeys(d.Teams) {
+				if logins := toSet(d.Teams[slug].Logins()); len(logins) == 1 {
+					findings = append(findings, lintFinding{Team: slug, Message: fmt.Sprintf("team %s has a single member", slug)})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		ID:          "no-maintainer",
+		Description: "Team has members but no maintainers",
+		Severity:    "error",
+		Check: func(d data, c *lintCommand) []lintFinding {
+			var findings []lintFinding
+			for _, slug := range sortedKeys(d.Teams) {
+				team := d.Teams[slug]
+				if len(team.Members) > 0 && len(team.Maintainers()) == 0 {
+					findings = append(findings, lintFinding{Team: slug, Message: fmt.Sprintf("team %s has no maintainers", slug)})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		ID:          "member-without-team",
+		Description: "Organization member is not in any team",
+		Severity:    "warning",
+		Check: func(d data, c *lintCommand) []lintFinding {
+			var findings []lintFinding
+			for _, login := range d.MembersWithoutTeam {
+				findings = append(findings, lintFinding{Login: login, Message: fmt.Sprintf("member %s is not in any team", login)})
+			}
+			return findings
+		},
+	},
+	{
+		ID:          "max-depth",
+		Description: "Team is nested too deep",
+		Severity:    "warning",
+		Check: func(d data, c *lintCommand) []lintFinding {
+			var findings []lintFinding
+			depths := teamDepths(d.Teams)
+			for _, slug := range sortedKeys(d.Teams) {
+				if depths[slug] > c.MaxDepth {
+					findings = append(findings, lintFinding{
+						Team:    slug,
+						Message: fmt.Sprintf("team %s is nested %d levels deep, more than %d", slug, depths[slug], c.MaxDepth),
+					})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		ID:          "duplicate-team",
+		Description: "Team has the same members as another team",
+		Severity:    "warning",
+		Check: func(d data, c *lintCommand) []lintFinding {
+			var findings []lintFinding
+			for _, group := range d.SubsetGroups {
+				for _, slug := range group[1:] {
+					findings = append(findings, lintFinding{
+						Team:    slug,
+						Message: fmt.Sprintf("team %s has the same members as %s", slug, group[0]),
+					})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		ID:          "team-name",
+		Description: "Team name does not match the name pattern",
+		Severity:    "error",
+		Check: func(d data, c *lintCommand) []lintFinding {
+			if c.namePattern == nil {
+				return nil
+			}
+
+			var findings []lintFinding
+			for _, slug := range sortedKeys(d.Teams) {
+				if name := d.Teams[slug].Name; !c.namePattern.MatchString(name) {
+					findings = append(findings, lintFinding{
+						Team:    slug,
+						Message: fmt.Sprintf("team name %q does not match %s", name, c.namePattern),
+					})
+				}
+			}
+			return findings
+		},
+	},
+}
+
+type lintCommand struct {
+	Format      string            `long:"format" description:"Output format" choice:"text" choice:"json" choice:"sarif" default:"text"`
+	Output      string            `long:"output" description:"Output file, standard output if empty"`
+	MaxDepth    int               `long:"max-depth" description:"Maximum nesting depth of teams" default:"3"`
+	NamePattern string            `long:"name-pattern" description:"Regular expression team names must match (optional)"`
+	Disable     []string          `long:"disable" description:"Rule to skip, can be repeated"`
+	Severity    map[string]string `long:"severity" description:"Severity of the rule, as rule:severity, can be repeated"`
+	FailOn      string            `long:"fail-on" description:"Exit with error if there are findings of this or higher severity" choice:"note" choice:"warning" choice:"error" choice:"none" default:"error"`
+
+	cfg         *config
+	namePattern *regexp.Regexp
+}
+
+func (c *lintCommand) Execute(args []string) error {
+	if c.NamePattern != "" {
+		var err error
+		c.namePattern, err = regexp.Compile(c.NamePattern)
+		if err != nil {
+			return fmt.Errorf("invalid name pattern: %w", err)
+		}
+	}
+
+	if c.cfg.HideMembers {
+		return fmt.Errorf("lint rules need team members, --hide-members is not supported")
+	}
+
+	snapshot, err := loadSnapshot(*c.cfg)
+	if err != nil {
+		return err
+	}
+
+	d := newData(snapshot.Organization)
+	if snapshot.HideMembers || len(allLogins(d)) == 0 {
+		return fmt.Errorf("lint rules need team members, organization has no members")
+	}
+
+	findings, err := c.lint(d)
+	if err != nil {
+		return err
+	}
+
+	switch c.Format {
+	case "json":
+		err = writeOutput(c.Output, func(w io.Writer) error {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(findings)
+		})
+	case "sarif":
+		err = writeOutput(c.Output, func(w io.Writer) error {
+			return writeSARIF(w, c.artifact(snapshot.Organization), c.severities(), findings)
+		})
+	default:
+		err = renderTemplate("", builtinTemplate("lint.txt.tmpl"), c.Output, findings)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write findings: %w", err)
+	}
+
+	if c.Output != "" {
+		log.Println("Done!")
+	}
+
+	switch n := countAtLeast(findings, c.FailOn); n {
+	case 0:
+	case 1:
+		return fmt.Errorf("1 finding of %s or higher severity", c.FailOn)
+	default:
+		return fmt.Errorf("%d findings of %s or higher severity", n, c.FailOn)
+	}
+
+	return nil
+}
+
+// lint runs enabled rules over the data.
+func (c *lintCommand) lint(d data) ([]lintFinding, error) {
+	rules := map[string]struct{}{}
+	for _, rule := range lintRules {
+		rules[rule.ID] = struct{}{}
+	}
+
+	disabled := toSet(c.Disable)
+	for id := range disabled {
+		if _, ok := rules[id]; !ok {
+			return nil, fmt.Errorf("unknown rule %q", id)
+		}
+	}
+	for id, severity := range c.Severity {
+		if _, ok := rules[id]; !ok {
+			return nil, fmt.Errorf("unknown rule %q", id)
+		}
+		if severityRank(severity) < 0 {
+			return nil, fmt.Errorf("unknown severity %q of rule %q", severity, id)
+		}
+	}
+
+	severities := c.severities()
+	findings := []lintFinding{}
+	for _, rule := range lintRules {
+		if _, ok := disabled[rule.ID]; ok {
+			continue
+		}
+
+		for _, f := range rule.Check(d, c) {
+			f.Rule, f.Severity = rule.ID, severities[rule.ID]
+			findings = append(findings, f)
+		}
+	}
+
+	return findings, nil
+}
+
+// severities returns severity of every rule, set with --severity or default.
+func (c *lintCommand) severities() map[string]string {
+	result := make(map[string]string, len(lintRules))
+	for _, rule := range lintRules {
+		result[rule.ID] = rule.Severity
+		if s, ok := c.Severity[rule.ID]; ok {
+			result[rule.ID] = s
+		}
+	}
+	return result
+}
+
+// artifact returns the file organization data was loaded from,
+// or teams page of the organization if it was fetched from GitHub.
+func (c *lintCommand) artifact(org *Organization) string {
+	switch {
+	case c.cfg.SnapshotIn != "":
+		return c.cfg.SnapshotIn
+	case c.cfg.YAMLIn != "":
+		return c.cfg.YAMLIn
+	default:
+		return "https://github.com/orgs/" + org.Login + "/teams"
+	}
+}
+
+// severityRank returns index of severity in lintSeverities, or -1 if it is unknown.
+func severityRank(severity string) int {
+	for i, s := range lintSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// countAtLeast returns the number of findings of severity threshold or higher,
+// none for unknown threshold.
+func countAtLeast(findings []lintFinding, threshold string) int {
+	rank := severityRank(threshold)
+	if rank < 0 {
+		return 0
+	}
+
+	n := 0
+	for _, f := range findings {
+		if severityRank(f.Severity) >= rank {
+			n++
+		}
+	}
+	return n
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// writeSARIF writes findings as SARIF 2.1.0 log, for code scanning tools.
+// Rules are configured with severities by rule ID,
+// findings are located in the artifact file or URL, and in their team or member.
+func writeSARIF(w io.Writer, artifact string, severities map[string]string, findings []lintFinding) error {
+	driver := sarifDriver{
+		Name:           "teams",
+		InformationURI: "https://github.com/chuhlomin/teams",
+	}
+	for _, rule := range lintRules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{rule.Description},
+			DefaultConfiguration: sarifConfiguration{severities[rule.ID]},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		location := sarifLocation{
+			PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: artifact}},
+		}
+		if f.Team != "" {
+			location.LogicalLocations = append(location.LogicalLocations, sarifLogicalLocation{Name: f.Team, Kind: "team"})
+		}
+		if f.Login != "" {
+			location.LogicalLocations = append(location.LogicalLocations, sarifLogicalLocation{Name: f.Login, Kind: "member"})
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     f.Severity,
+			Message:   sarifMessage{f.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShouldLintOrganization(t *testing.T) {
+	c := &lintCommand{MaxDepth: 3}
+
+	findings, err := c.lint(newData(newTestOrganization()))
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+
+	expected := []lintFinding{
+		{Rule: "single-member-team", Severity: "note", Team: "test-team-2", Message: "team test-team-2 has a single member"},
+		{Rule: "single-member-team", Severity: "note", Team: "test-team-3", Message: "team test-team-3 has a single member"},
+		{Rule: "no-maintainer", Severity: "error", Team: "test-team-2", Message: "team test-team-2 has no maintainers"},
+		{Rule: "no-maintainer", Severity: "error", Team: "test-team-3", Message: "team test-team-3 has no maintainers"},
+		{Rule: "member-without-team", Severity: "warning", Login: "test-user-3", Message: "member test-user-3 is not in any team"},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("Expected findings to be %+v, got %+v", expected, findings)
+	}
+}
+
+func TestShouldConfigureLintRules(t *testing.T) {
+	org := newTestOrganization()
+	org.Teams["test-team-3"].Parent = "test-team-2"
+	org.Teams["test-team-4"] = &Team{Slug: "test-team-4", Name: "test-team-4", Members: []Member{{Login: "test-user"}}}
+
+	c := &lintCommand{
+		MaxDepth:    1,
+		Disable:     []string{"single-member-team", "no-maintainer", "member-without-team"},
+		Severity:    map[string]string{"max-depth": "error"},
+		namePattern: regexp.MustCompile(`^[a-z0-9-]+$`),
+	}
+
+	findings, err := c.lint(newData(org))
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+
+	expected := []lintFinding{
+		{Rule: "max-depth", Severity: "error", Team: "test-team-3", Message: "team test-team-3 is nested 2 levels deep, more than 1"},
+		{Rule: "duplicate-team", Severity: "warning", Team: "test-team-4", Message: "team test-team-4 has the same members as test-team-2"},
+		{Rule: "team-name", Severity: "error", Team: "test-team", Message: "team name \"Test \\\"Team\\\" | <1> & co\" does not match ^[a-z0-9-]+$"},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("Expected findings to be %+v, got %+v", expected, findings)
+	}
+}
+
+func TestShouldFailOnUnknownLintRule(t *testing.T) {
+	for _, c := range []*lintCommand{
+		{Disable: []string{"unknown"}},
+		{Severity: map[string]string{"unknown": "error"}},
+		{Severity: map[string]string{"empty-team": "fatal"}},
+	} {
+		if _, err := c.lint(newData(newTestOrganization())); err == nil {
+			t.Errorf("Expected error for %+v", c)
+		}
+	}
+}
+
+func TestShouldCountFindingsAboveSeverity(t *testing.T) {
+	findings := []lintFinding{{Severity: "note"}, {Severity: "warning"}, {Severity: "error"}}
+
+	for threshold, expected := range map[string]int{"note": 3, "warning": 2, "error": 1, "none": 0} {
+		if got := countAtLeast(findings, threshold); got != expected {
+			t.Errorf("Expected %d findings of %s or higher severity, got %d", expected, threshold, got)
+		}
+	}
+}
+
+func TestShouldWriteSARIF(t *testing.T) {
+	findings := []lintFinding{
+		{Rule: "no-maintainer", Severity: "error", Team: "test-team", Message: "team test-team has no maintainers"},
+		{Rule: "member-without-team", Severity: "warning", Login: "test-user", Message: "member test-user is not in any team"},
+	}
+
+	c := &lintCommand{Severity: map[string]string{"member-without-team": "note"}, cfg: &config{}}
+	artifact := c.artifact(&Organization{Login: "test-org"})
+
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, artifact, c.severities(), findings); err != nil {
+		t.Fatalf("Error writing SARIF: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Error parsing SARIF: %v\n%s", err, buf.String())
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Expected SARIF 2.1.0 log with a run, got:\n%s", buf.String())
+	}
+	if len(log.Runs[0].Tool.Driver.Rules) != len(lintRules) {
+		t.Errorf("Expected %d rules, got %d", len(lintRules), len(log.Runs[0].Tool.Driver.Rules))
+	}
+	for _, rule := range log.Runs[0].Tool.Driver.Rules {
+		if rule.ID == "member-without-team" && rule.DefaultConfiguration.Level != "note" {
+			t.Errorf("Expected member-without-team rule level to be note, got %s", rule.DefaultConfiguration.Level)
+		}
+	}
+
+	expected := []sarifResult{
+		{
+			RuleID:  "no-maintainer",
+			Level:   "error",
+			Message: sarifMessage{"team test-team has no maintainers"},
+			Locations: []sarifLocation{{
+				PhysicalLocation: &sarifPhysicalLocation{sarifArtifactLocation{"https://github.com/orgs/test-org/teams"}},
+				LogicalLocations: []sarifLogicalLocation{{Name: "test-team", Kind: "team"}},
+			}},
+		},
+		{
+			RuleID:  "member-without-team",
+			Level:   "warning",
+			Message: sarifMessage{"member test-user is not in any team"},
+			Locations: []sarifLocation{{
+				PhysicalLocation: &sarifPhysicalLocation{sarifArtifactLocation{"https://github.com/orgs/test-org/teams"}},
+				LogicalLocations: []sarifLogicalLocation{{Name: "test-user", Kind: "member"}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(log.Runs[0].Results, expected) {
+		t.Errorf("Expected results to be %+v, got %+v", expected, log.Runs[0].Results)
+	}
+}
+
+func TestShouldExitWithErrorAboveSeverity(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "teams.yaml")
+	err := os.WriteFile(path, []byte(`
+organization: test-org
+teams:
+  - name: test-team
+    maintainers: [test-user]
+    members: [test-user-2]
+  - name: test-team-2
+    members: [test-user-3]
+`), 0o644)
+	if err != nil {
+		t.Fatalf("Error writing teams file: %v", err)
+	}
+
+	output := filepath.Join(dir, "lint.json")
+	c := &lintCommand{Format: "json", Output: output, MaxDepth: 3, FailOn: "error", cfg: &config{YAMLIn: path}}
+	if err := c.Execute(nil); err == nil {
+		t.Error("Expected error for team without maintainers")
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	var findings []lintFinding
+	if err := json.Unmarshal(b, &findings); err != nil {
+		t.Fatalf("Error parsing output: %v\n%s", err, b)
+	}
+	if len(findings) != 2 {
+		t.Errorf("Expected 2 findings, got %+v", findings)
+	}
+
+	c.FailOn = "none"
+	if err := c.Execute(nil); err != nil {
+		t.Errorf("Expected no error with --fail-on=none, got %v", err)
+	}
+}
+
+func TestShouldNotLintHiddenMembers(t *testing.T) {
+	c := &lintCommand{cfg: &config{HideMembers: true, YAMLIn: "teams.yaml"}}
+	if err := c.Execute(nil); err == nil || !strings.Contains(err.Error(), "--hide-members") {
+		t.Errorf("Expected error for --hide-members, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "org.json")
+	snapshot := newSnapshot(&Organization{Teams: newTestTeams(map[string][]string{"test-team": {}})}, time.Now(), time.Now())
+	snapshot.HideMembers = true
+	if err := writeSnapshot(path, snapshot); err != nil {
+		t.Fatalf("Error writing snapshot: %v", err)
+	}
+
+	c = &lintCommand{cfg: &config{SnapshotIn: path}}
+	if err := c.Execute(nil); err == nil || !strings.Contains(err.Error(), "no members") {
+		t.Errorf("Expected error for snapshot without members, got %v", err)
+	}
+}
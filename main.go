@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
-	_ "embed"
+	"fmt"
+	"io"
 	"log"
 	"os"
-	"strings"
+	"path/filepath"
+	"sort"
 	"text/template"
+	"time"
 
 	"github.com/google/go-github/v48/github"
 	flags "github.com/jessevdk/go-flags"
@@ -14,25 +17,191 @@ import (
 )
 
 type config struct {
-	Token       string `env:"GITHUB_TOKEN" long:"token" description:"GitHub access token" required:"true"`
-	OrgName     string `env:"GITHUB_ORG" long:"org" description:"GitHub organization name" required:"true"`
-	HideMembers bool   `env:"HIDE_MEMBERS" long:"hide-members" description:"Hide Team Members on the diagram"`
-	Template    string `env:"TEMPLATE" long:"template" description:"Go template (optional)" default:""`
-	Output      string `env:"OUTPUT" long:"output" description:"Output file" default:"output/graph.dot"`
+	Token               string  `env:"GITHUB_TOKEN" long:"token" description:"GitHub access token"`
+	OrgName             string  `env:"GITHUB_ORG" long:"org" description:"GitHub organization name"`
+	HideMembers         bool    `env:"HIDE_MEMBERS" long:"hide-members" description:"Hide Team Members on the diagram"`
+	GraphQL             bool    `env:"GRAPHQL" long:"graphql" description:"Use GitHub GraphQL API to fetch teams and their members"`
+	Concurrency         int     `env:"CONCURRENCY" long:"concurrency" description:"Number of teams to fetch members for in parallel" default:"4"`
+	MaxRetries          int     `env:"MAX_RETRIES" long:"max-retries" description:"Number of retries for rate limited and failed GitHub API requests" default:"5"`
+	Format              string  `env:"FORMAT" long:"format" description:"Output format" choice:"dot" choice:"mermaid" choice:"plantuml" choice:"d2" choice:"markdown" choice:"report" choice:"csv" choice:"json" choice:"html" choice:"matrix" choice:"graphml" choice:"gexf" choice:"terraform" choice:"yaml" choice:"backstage" choice:"svg" default:"dot"`
+	Layout              string  `env:"LAYOUT" long:"layout" description:"Diagram layout of dot format: teams with member logins inside, members as separate nodes, or child teams inside their parents" choice:"records" choice:"members" choice:"clusters" default:"records"`
+	CollapseDepth       int     `env:"COLLAPSE_DEPTH" long:"collapse-depth" description:"Collapse teams nested deeper than this number of levels into a single node in clusters layout, 0 to draw all levels" default:"0"`
+	Similarity          string  `env:"SIMILARITY" long:"similarity" description:"Similarity measure of overlapping teams: shared members divided by members of both teams, or of the smaller team" choice:"jaccard" choice:"overlap" default:"jaccard"`
+	SimilarityThreshold float64 `env:"SIMILARITY_THRESHOLD" long:"similarity-threshold" description:"Minimum similarity of overlapping teams, greater than 0 and at most 1" default:"0.8"`
+	SimilarityEdges     bool    `env:"SIMILARITY_EDGES" long:"similarity-edges" description:"Connect overlapping teams with dotted edges weighted by similarity in dot format"`
+	Template            string  `env:"TEMPLATE" long:"template" description:"Go template, overrides format (optional)" default:""`
+	Output              string  `env:"OUTPUT" long:"output" description:"Output file (default: output/graph with format extension)"`
+	TerraformImports    string  `env:"TERRAFORM_IMPORTS" long:"terraform-imports" description:"Terraform import blocks file for terraform format (default: imports.tf next to output, or stdout after resources)"`
+	SnapshotOut         string  `env:"SNAPSHOT_OUT" long:"snapshot-out" description:"Save fetched organization data to JSON file (optional)"`
+	SnapshotIn          string  `env:"SNAPSHOT_IN" long:"snapshot-in" description:"Load organization data from JSON file instead of GitHub API (optional)"`
+	YAMLIn              string  `env:"YAML_IN" long:"yaml-in" description:"Load organization data from teams.yaml file instead of GitHub API (optional)"`
 }
 
 func main() {
 	var cfg config
-	_, err := flags.Parse(&cfg)
+	// errors are printed once below, not by the parser
+	parser := flags.NewParser(&cfg, flags.HelpFlag|flags.PassDoubleDash)
+	parser.SubcommandsOptional = true
+
+	_, err := parser.AddCommand(
+		"diff",
+		"Compare two snapshots",
+		"Compare two organization snapshots saved with --snapshot-out",
+		&diffCommand{},
+	)
+	if err != nil {
+		log.Fatalf("Error adding command: %v", err)
+	}
+
+	_, err = parser.AddCommand(
+		"lint",
+		"Check organization hygiene",
+		"Check teams and members of the organization with lint rules, exit with error on findings of --fail-on or higher severity",
+		&lintCommand{cfg: &cfg},
+	)
+	if err != nil {
+		log.Fatalf("Error adding command: %v", err)
+	}
+
+	_, err = parser.Parse()
 	if err != nil {
-		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
-			os.Exit(0)
-			return
+		if flagsErr, ok := err.(*flags.Error); ok {
+			if flagsErr.Type == flags.ErrHelp {
+				fmt.Println(flagsErr.Message)
+				os.Exit(0)
+				return
+			}
+			log.Fatalf("Error parsing flags: %v", err)
+		}
+		log.Fatalf("Error running %s command: %v", parser.Active.Name, err)
+	}
+
+	if parser.Active != nil {
+		return
+	}
+
+	overlaps := cfg.usesOverlaps()
+	if overlaps {
+		if err := checkSimilarity(cfg.Similarity, cfg.SimilarityThreshold); err != nil {
+			log.Fatalf("Error parsing flags: %v", err)
+		}
+	}
+
+	snapshot, err := loadSnapshot(cfg)
+	if err != nil {
+		log.Fatalf("Error loading organization: %v", err)
+	}
+
+	if cfg.SnapshotOut != "" {
+		log.Println("Saving snapshot...")
+		if err := writeSnapshot(cfg.SnapshotOut, snapshot); err != nil {
+			log.Fatalf("Error saving snapshot: %v", err)
+		}
+	}
+
+	f := formats[cfg.Format]
+	if cfg.Format == "dot" {
+		f.Template = dotLayouts[cfg.Layout]
+	}
+
+	output := cfg.Output
+	if output == "" {
+		output = "output/graph" + f.Ext
+	}
+
+	d := newData(snapshot.Organization)
+	d.CollapseDepth = cfg.CollapseDepth
+	d.Similarity, d.SimilarityThreshold, d.SimilarityEdges = cfg.Similarity, cfg.SimilarityThreshold, cfg.SimilarityEdges
+	if overlaps {
+		d.Overlaps, err = FindOverlaps(d.Teams, d.Similarity, d.SimilarityThreshold)
+		if err != nil {
+			log.Fatalf("Error finding overlapping teams: %v", err)
+		}
+	}
+
+	log.Println("Rendering output...")
+	if err := f.render(cfg.Template, output, d); err != nil {
+		log.Fatalf("Error rendering output: %v", err)
+	}
+
+	if cfg.Format == "terraform" && cfg.Template == "" {
+		imports := terraformImportsPath(cfg.TerraformImports, output)
+
+		log.Println("Writing Terraform imports...")
+		err := writeOutput(imports, func(w io.Writer) error {
+			if imports == "-" && output == "-" {
+				fmt.Fprintln(w) // separate import blocks from resources
+			}
+			return writeTerraformImports(w, d)
+		})
+		if err != nil {
+			log.Fatalf("Error writing Terraform imports: %v", err)
 		}
-		log.Fatalf("Error parsing flags: %v", err)
 	}
 
-	ctx := context.Background()
+	log.Println("Done!")
+}
+
+// terraformImportsPath returns file for Terraform import blocks:
+// imports.tf next to the output file, or stdout after the resources if output is stdout.
+func terraformImportsPath(imports, output string) string {
+	switch {
+	case imports != "":
+		return imports
+	case output == "-":
+		return "-"
+	default:
+		return filepath.Join(filepath.Dir(output), "imports.tf")
+	}
+}
+
+// usesOverlaps reports whether output lists overlapping teams:
+// formats that do, dot format with similarity edges, and custom templates.
+func (cfg config) usesOverlaps() bool {
+	if cfg.Template != "" {
+		return true
+	}
+
+	return formats[cfg.Format].Overlaps || cfg.Format == "dot" && cfg.SimilarityEdges
+}
+
+// loadSnapshot loads organization data from the snapshot or teams.yaml file,
+// or fetches it from GitHub API.
+func loadSnapshot(cfg config) (*Snapshot, error) {
+	switch {
+	case cfg.SnapshotIn != "":
+		log.Println("Loading snapshot...")
+		snapshot, err := readSnapshot(cfg.SnapshotIn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot: %w", err)
+		}
+		return snapshot, nil
+	case cfg.YAMLIn != "":
+		log.Println("Loading teams file...")
+		org, err := readTeamsYAML(cfg.YAMLIn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load teams file: %w", err)
+		}
+		if cfg.OrgName != "" {
+			org.Login = cfg.OrgName
+		}
+		return newSnapshot(org, time.Now(), time.Now()), nil
+	default:
+		if cfg.Token == "" || cfg.OrgName == "" {
+			return nil, fmt.Errorf("--token and --org are required unless --snapshot-in or --yaml-in is set")
+		}
+
+		snapshot, err := fetch(context.Background(), cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch organization: %w", err)
+		}
+		return snapshot, nil
+	}
+}
+
+// fetch gets organization teams and members from GitHub API.
+func fetch(ctx context.Context, cfg config) (*Snapshot, error) {
+	startedAt := time.Now()
 
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: cfg.Token},
@@ -40,50 +209,50 @@ func main() {
 	tc := oauth2.NewClient(ctx, ts)
 	client := github.NewClient(tc)
 
+	r := newRetrier(cfg.MaxRetries)
+
 	processor := Processor{
 		Context:              ctx,
-		OrganizationsService: client.Organizations,
-		TeamsService:         client.Teams,
+		OrganizationsService: &retryOrganizationsService{client.Organizations, r},
+		TeamsService:         &retryTeamsService{client.Teams, r},
 		HideMembers:          cfg.HideMembers,
+		Concurrency:          cfg.Concurrency,
+	}
+
+	if cfg.GraphQL {
+		processor.GraphQLService = &retryGraphQLService{&GraphQLClient{HTTPClient: tc}, r}
 	}
 
 	log.Println("Getting organization ID...")
 	orgID, err := processor.GetOrganizationID(cfg.OrgName)
 	if err != nil {
-		log.Fatalf("Error checking organization access: %v", err)
+		return nil, fmt.Errorf("failed to check organization access: %w", err)
 	}
 
 	log.Println("Getting organization teams...")
-	teams, parents, err := processor.Teams(cfg.OrgName, orgID)
+	teams, err := processor.Teams(cfg.OrgName, orgID)
 	if err != nil {
-		log.Fatalf("Error getting teams: %v", err)
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	org := &Organization{
+		ID:    orgID,
+		Login: cfg.OrgName,
+		Teams: teams,
 	}
 
-	var members []string
 	if !cfg.HideMembers {
 		log.Println("Getting organization members...")
-		members, err := processor.Members(cfg.OrgName)
+		org.Members, err = processor.Members(cfg.OrgName)
 		if err != nil {
-			log.Fatalf("Error getting members: %v", err)
-		}
-
-		membersWitoutTeam := FindMembersWithoutTeam(teams, members)
-		if len(membersWitoutTeam) > 0 {
-			teams["NO_TEAM"] = membersWitoutTeam
+			return nil, fmt.Errorf("failed to get members: %w", err)
 		}
 	}
 
-	log.Println("Rendering template...")
-	if err := renderTemplate(cfg.Template, cfg.Output, data{
-		Teams:   teams,
-		Parents: parents,
-		Members: members,
-		Subsets: FindSubsets(teams),
-	}); err != nil {
-		log.Fatalf("Error rendering template: %v", err)
-	}
+	snapshot := newSnapshot(org, startedAt, time.Now())
+	snapshot.HideMembers = cfg.HideMembers
 
-	log.Println("Done!")
+	return snapshot, nil
 }
 
 type subsets map[string]map[string]struct{}
@@ -117,61 +286,150 @@ func (s subsets) GetSubsets(team string) []string {
 	return subsets
 }
 
-func FindSubsets(teams map[string][]string) subsets {
+// FindSubsets returns teams whose members are all members of another team.
+// Teams with identical members are subsets of each other, teams without members are skipped.
+//
+// Logins are interned to sequential IDs, so every team has a bitset of its members.
+// A team can only be a subset of teams that have its member in the fewest teams,
+// so only those candidates are checked against the bitset.
+func FindSubsets(teams map[string]*Team) subsets {
 	var s subsets = map[string]map[string]struct{}{}
 
-	for team, members := range teams {
-		if len(members) == 0 {
-			continue
-		}
+	ids := map[string]int{}                       // login ID by login
+	members := make(map[string][]int, len(teams)) // unique login IDs by team
+	teamsOf := [][]string{}                       // teams by login ID
+	for team, t := range teams {
+		for _, member := range t.Members {
+			id, ok := ids[member.Login]
+			if !ok {
+				id = len(ids)
+				ids[member.Login] = id
+				teamsOf = append(teamsOf, nil)
+			}
 
-		for otherTeam, otherMembers := range teams {
-			if team == otherTeam {
+			// skip duplicate logins of the team
+			if n := len(teamsOf[id]); n > 0 && teamsOf[id][n-1] == team {
 				continue
 			}
+			teamsOf[id] = append(teamsOf[id], team)
+			members[team] = append(members[team], id)
+		}
+	}
 
-			if len(otherMembers) == 0 {
-				continue
+	words := (len(ids) + 63) / 64
+	bitsets := make(map[string][]uint64, len(members))
+	for team, teamMembers := range members {
+		bits := make([]uint64, words)
+		for _, id := range teamMembers {
+			bits[id/64] |= 1 << (id % 64)
+		}
+		bitsets[team] = bits
+	}
+
+	for team, teamMembers := range members {
+		rarest := teamMembers[0]
+		for _, id := range teamMembers[1:] {
+			if len(teamsOf[id]) < len(teamsOf[rarest]) {
+				rarest = id
 			}
+		}
 
-			// check if we already found a subset
-			if s.IsSubset(team, otherTeam) || s.IsSubset(otherTeam, team) {
-				// already found a subset
+	candidates:
+		for _, otherTeam := range teamsOf[rarest] {
+			if otherTeam == team || len(members[otherTeam]) < len(teamMembers) {
 				continue
 			}
 
-			// find common members
-			var commonMembers []string
-			for _, member := range members {
-				for _, otherMember := range otherMembers {
-					if member == otherMember {
-						commonMembers = append(commonMembers, member)
-					}
+			bits := bitsets[otherTeam]
+			for _, id := range teamMembers {
+				if bits[id/64]&(1<<(id%64)) == 0 {
+					continue candidates
 				}
 			}
 
-			if len(commonMembers) == 0 {
-				continue
+			s.AddSubset(team, otherTeam)
+		}
+	}
+
+	return s
+}
+
+// ReduceSubsets returns groups of teams with identical members,
+// and subsets between groups without relations implied by transitivity:
+// if A ⊂ B ⊂ C, only A ⊂ B and B ⊂ C are kept.
+// Groups are represented by their first team slug, groups of one team are not returned.
+func ReduceSubsets(s subsets) (subsets, [][]string) {
+	// teams are subsets of each other only if they have identical members
+	group := map[string]string{} // group representative by team slug
+	members := map[string][]string{}
+	for team := range s {
+		group[team] = team
+	}
+	for _, team := range sortedSubsetKeys(s) {
+		if group[team] != team {
+			continue
+		}
+		for _, other := range s.GetSubsets(team) {
+			if other > team && s.IsSubset(other, team) {
+				group[other] = team
+				members[team] = append(members[team], other)
 			}
+		}
+	}
+
+	var groups [][]string
+	for _, team := range sortedSubsetKeys(s) {
+		if others, ok := members[team]; ok {
+			sort.Strings(others)
+			groups = append(groups, append([]string{team}, others...))
+		}
+	}
 
-			if len(commonMembers) == len(members) {
-				s.AddSubset(team, otherTeam)
+	// strict subsets between groups
+	var strict subsets = map[string]map[string]struct{}{}
+	for team := range s {
+		for _, other := range s.GetSubsets(team) {
+			from, to := group[team], other
+			if g, ok := group[other]; ok {
+				to = g
+			}
+			if from != to {
+				strict.AddSubset(from, to)
 			}
+		}
+	}
 
-			if len(commonMembers) == len(otherMembers) {
-				s.AddSubset(otherTeam, team)
+	var reduced subsets = map[string]map[string]struct{}{}
+	for team, supersets := range strict {
+	supersets:
+		for superset := range supersets {
+			for between := range supersets {
+				if strict.IsSubset(between, superset) {
+					continue supersets
+				}
 			}
+			reduced.AddSubset(team, superset)
 		}
 	}
 
-	return s
+	return reduced, groups
+}
+
+func sortedSubsetKeys(s subsets) []string {
+	keys := make([]string, 0, len(s))
+	for team := range s {
+		keys = append(keys, team)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
 
-func FindMembersWithoutTeam(teams map[string][]string, members []string) []string {
+func FindMembersWithoutTeam(teams map[string]*Team, members []string) []string {
 	var existingMembers = make(map[string]struct{})
-	for _, teamMembers := range teams {
-		for _, member := range teamMembers {
-			existingMembers[member] = struct{}{}
+	for _, team := range teams {
+		for _, member := range team.Members {
+			existingMembers[member.Login] = struct{}{}
 		}
 	}
 
@@ -186,29 +444,45 @@ func FindMembersWithoutTeam(teams map[string][]string, members []string) []strin
 }
 
 type data struct {
-	Teams              map[string][]string
-	Parents            map[string]string
-	Members            []string
-	Subsets            subsets
-	MembersWithoutTeam []string
+	Org                 *Organization    `json:"organization"`
+	Teams               map[string]*Team `json:"-"` // keyed by team slug
+	Members             []string         `json:"-"`
+	Subsets             subsets          `json:"subsets"`
+	ReducedSubsets      subsets          `json:"reduced_subsets"` // subsets without transitive relations, between SubsetGroups
+	SubsetGroups        [][]string       `json:"subset_groups"`   // teams with identical members
+	MembersWithoutTeam  []string         `json:"members_without_team"`
+	CollapseDepth       int              `json:"-"` // nesting levels drawn by clusters layout, 0 for all
+	Overlaps            []teamOverlap    `json:"overlaps"`
+	Similarity          string           `json:"-"` // measure of Overlaps
+	SimilarityThreshold float64          `json:"-"` // minimum similarity of Overlaps
+	SimilarityEdges     bool             `json:"-"` // draw Overlaps in dot format
 }
 
-var funcMap = template.FuncMap{
-	"join": func(a []string, sep string) string {
-		return strings.Join(a, sep)
-	},
+func newData(org *Organization) data {
+	s := FindSubsets(org.Teams)
+	reduced, groups := ReduceSubsets(s)
+
+	return data{
+		Org:                org,
+		Teams:              org.Teams,
+		Members:            org.Members,
+		Subsets:            s,
+		ReducedSubsets:     reduced,
+		SubsetGroups:       groups,
+		MembersWithoutTeam: FindMembersWithoutTeam(org.Teams, org.Members),
+	}
 }
 
-//go:embed dot.tmpl
-var dotTemplate string
-
-func renderTemplate(tmpl, output string, data data) error {
+// renderTemplate renders data to the output file using template file tmpl,
+// or template text if tmpl is empty.
+func renderTemplate(tmpl, text, output string, data interface{}) error {
 	var err error
 
-	t := template.New(tmpl).Funcs(funcMap)
+	name := filepath.Base(tmpl)
+	t := template.New(name).Funcs(funcMap)
 
 	if tmpl == "" {
-		t, err = t.Parse(dotTemplate)
+		t, err = t.Parse(text)
 	} else {
 		t, err = t.ParseFiles(tmpl)
 	}
@@ -217,11 +491,23 @@ func renderTemplate(tmpl, output string, data data) error {
 		return err
 	}
 
+	return writeOutput(output, func(w io.Writer) error {
+		return t.ExecuteTemplate(w, name, data)
+	})
+}
+
+// writeOutput calls write with the output file,
+// or with standard output if output is empty or "-".
+func writeOutput(output string, write func(w io.Writer) error) error {
+	if output == "" || output == "-" {
+		return write(os.Stdout)
+	}
+
 	f, err := os.Create(output)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	return t.ExecuteTemplate(f, tmpl, data)
+	return write(f)
 }
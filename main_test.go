@@ -1,19 +1,38 @@
 package main
 
 import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
+// newTestTeams returns teams keyed by slug with given member logins.
+func newTestTeams(teamMembers map[string][]string) map[string]*Team {
+	teams := make(map[string]*Team, len(teamMembers))
+	for slug, logins := range teamMembers {
+		team := &Team{Slug: slug, Name: slug}
+		for _, login := range logins {
+			team.Members = append(team.Members, Member{Login: login})
+		}
+		teams[slug] = team
+	}
+
+	return teams
+}
+
 func TestShouldFindSubsets(t *testing.T) {
-	teams := map[string][]string{
+	teams := newTestTeams(map[string][]string{
 		"test-team":   {"test-user", "test-user-2", "test-user-3"},
 		"test-team-2": {"test-user", "test-user-2"},
 		"test-team-3": {"test-user-3", "test-user-4"},
 		"test-team-4": {"test-user-3", "test-user-4"},
 		"test-team-5": {"test-user", "test-user-2", "test-user-4"},
-	}
+	})
 
 	subsets := FindSubsets(teams)
 
@@ -33,11 +52,131 @@ func TestShouldFindSubsets(t *testing.T) {
 	}
 }
 
+func TestShouldReduceSubsets(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a":   {"test-user"},
+		"a-2": {"test-user"},
+		"a-3": {"test-user"},
+		"b":   {"test-user", "test-user-2"},
+		"c":   {"test-user", "test-user-2", "test-user-3"},
+		"d":   {"test-user", "test-user-4"},
+	})
+
+	reduced, groups := ReduceSubsets(FindSubsets(teams))
+
+	expected := map[string][]string{
+		"a": {"b", "d"},
+		"b": {"c"},
+	}
+	if len(reduced) != len(expected) {
+		t.Errorf("Expected reduced subsets to be %v, got %v", expected, reduced)
+	}
+	for team, expectedSubsets := range expected {
+		teamSubsets := reduced.GetSubsets(team)
+		sort.Strings(teamSubsets)
+
+		if !reflect.DeepEqual(teamSubsets, expectedSubsets) {
+			t.Errorf("Expected reduced subsets for team %s to be %v, got %v", team, expectedSubsets, teamSubsets)
+		}
+	}
+
+	expectedGroups := [][]string{{"a", "a-2", "a-3"}}
+	if !reflect.DeepEqual(groups, expectedGroups) {
+		t.Errorf("Expected subset groups to be %v, got %v", expectedGroups, groups)
+	}
+}
+
+// newRandomTeams returns teams with random members,
+// some of them copies or subsets of other teams.
+func newRandomTeams(r *rand.Rand, teamsCount, membersCount int) map[string]*Team {
+	teams := make(map[string]*Team, teamsCount)
+	var slugs []string
+	for i := 0; i < teamsCount; i++ {
+		slug := fmt.Sprintf("team-%d", i)
+		team := &Team{Slug: slug, Name: slug}
+
+		switch n := r.Intn(10); {
+		case n == 0 && i > 0: // copy of another team
+			team.Members = append(team.Members, teams[slugs[r.Intn(i)]].Members...)
+		case n < 4 && i > 0: // subset of another team
+			for _, member := range teams[slugs[r.Intn(i)]].Members {
+				if r.Intn(2) == 0 {
+					team.Members = append(team.Members, member)
+				}
+			}
+		case n < 5: // empty team
+		default:
+			for j := r.Intn(40); j >= 0; j-- {
+				team.Members = append(team.Members, Member{Login: fmt.Sprintf("user-%d", r.Intn(membersCount))})
+			}
+		}
+
+		teams[slug] = team
+		slugs = append(slugs, slug)
+	}
+
+	return teams
+}
+
+// findSubsetsNaive compares members of every pair of teams.
+func findSubsetsNaive(teams map[string]*Team) subsets {
+	var s subsets = map[string]map[string]struct{}{}
+	for team, t := range teams {
+		members := toSet(t.Logins())
+		for otherTeam, ot := range teams {
+			otherMembers := toSet(ot.Logins())
+			if team == otherTeam || len(members) == 0 || len(otherMembers) == 0 {
+				continue
+			}
+
+			subset := true
+			for member := range members {
+				if _, ok := otherMembers[member]; !ok {
+					subset = false
+					break
+				}
+			}
+			if subset {
+				s.AddSubset(team, otherTeam)
+			}
+		}
+	}
+
+	return s
+}
+
+func TestShouldFindSameSubsetsAsNaive(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		teams := newRandomTeams(r, 200, 300)
+
+		expected := findSubsetsNaive(teams)
+		if got := FindSubsets(teams); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Expected subsets to be %v, got %v", expected, got)
+		}
+	}
+}
+
+func BenchmarkFindSubsets(b *testing.B) {
+	for _, size := range []struct{ teams, members int }{
+		{500, 2000},
+		{5000, 20000},
+	} {
+		b.Run(fmt.Sprintf("%dx%d", size.teams, size.members), func(b *testing.B) {
+			teams := newRandomTeams(rand.New(rand.NewSource(1)), size.teams, size.members)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				FindSubsets(teams)
+			}
+		})
+	}
+}
+
 func TestShouldFindMembersWithoutTeam(t *testing.T) {
-	teams := map[string][]string{
+	teams := newTestTeams(map[string][]string{
 		"test-team":   {"test-user", "test-user-2", "test-user-3"},
 		"test-team-2": {"test-user", "test-user-2"},
-	}
+	})
 
 	members := []string{"test-user", "test-user-2", "test-user-3", "test-user-4"}
 
@@ -49,3 +188,150 @@ func TestShouldFindMembersWithoutTeam(t *testing.T) {
 		t.Errorf("Expected members without team to be %v, got %v", expected, membersWithoutTeam)
 	}
 }
+
+func TestShouldRenderTemplate(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-2"},
+		"test-team-2": {"test-user"},
+	})
+	teams["test-team-2"].Parent = "test-team"
+	teams["test-team"].Members[1].Role = RoleMaintainer
+
+	org := &Organization{
+		Login:   "test-org",
+		Teams:   teams,
+		Members: []string{"test-user", "test-user-2", "test-user-3"},
+	}
+
+	output := filepath.Join(t.TempDir(), "graph.dot")
+	if err := renderTemplate("", builtinTemplate("dot.tmpl"), output, newData(org)); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	for _, expected := range []string{
+		`"test-team" [ label="{*test-team*|test-user|★ test-user-2}" ]`,
+		`"test-team-2" [ label="{*test-team-2*|test-user}"; color=red ]`,
+		`"NO_TEAM" [ label="{*NO_TEAM*|test-user-3}" ]`,
+		`"test-team" -> "test-team-2" [penwidth=1.5];`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
+
+func TestShouldRenderReducedSubsets(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a":   {"test-user"},
+		"a-2": {"test-user"},
+		"b":   {"test-user", "test-user-2"},
+		"c":   {"test-user", "test-user-2", "test-user-3"},
+	})
+
+	output := filepath.Join(t.TempDir(), "graph.dot")
+	if err := renderTemplate("", builtinTemplate("dot.tmpl"), output, newData(&Organization{Teams: teams})); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	for _, expected := range []string{
+		`"b" -> "a" [style=dashed];`,
+		`"c" -> "b" [style=dashed];`,
+		`"a" -> "a-2" [style=dashed; dir=none; label="="];`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+		}
+	}
+
+	for _, unexpected := range []string{`"c" -> "a"`, `"b" -> "a-2"`, `"a-2" -> "a"`} {
+		if strings.Contains(string(b), unexpected) {
+			t.Errorf("Expected output not to contain %q, got:\n%s", unexpected, b)
+		}
+	}
+}
+
+func TestShouldRenderMermaidTemplate(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-2"},
+		"test-team-2": {"test-user"},
+	})
+	teams["test-team"].Name = `Test "Team"`
+	teams["test-team-2"].Parent = "test-team"
+	teams["test-team-2"].Members[0].Role = RoleMaintainer
+
+	org := &Organization{
+		Login: "test-org",
+		Teams: teams,
+	}
+
+	output := filepath.Join(t.TempDir(), "graph.mmd")
+	if err := renderTemplate("", builtinTemplate("mermaid.tmpl"), output, newData(org)); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	for _, expected := range []string{
+		`team_test_2dteam["<b>Test #quot;Team#quot;</b><br/>test-user<br/>test-user-2"]:::noMaintainer`,
+		`team_test_2dteam_2d2["<b>test-team-2</b><br/>★ test-user"]`,
+		`team_test_2dteam --> team_test_2dteam_2d2`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
+
+func TestShouldRenderMembersTemplate(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"test-team":   {"test-user", "test-user-2"},
+		"test-team-2": {"test-user"},
+		"test-team-3": {"test-user"},
+		"test-team-4": {"test-user"},
+	})
+	teams["test-team"].Name = `Test "Team"`
+	teams["test-team"].Members[1].Role = RoleMaintainer
+
+	org := &Organization{
+		Login:   "test-org",
+		Teams:   teams,
+		Members: []string{"test-user", "test-user-2", "test-user-3"},
+	}
+
+	output := filepath.Join(t.TempDir(), "graph.dot")
+	if err := renderTemplate("", builtinTemplate("dot-members.tmpl"), output, newData(org)); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	for _, expected := range []string{
+		`team_test_2dteam [ shape=box; label="Test \"Team\"" ]`,
+		`team_test_2dteam_2d2 [ shape=box; label="test-team-2"; color=red ]`,
+		`user_test_2duser [ shape=ellipse; label="test-user (4)"; style=filled; fillcolor="#ffd27f"; penwidth=2; fontsize=12 ]`,
+		`user_test_2duser_2d2 [ shape=ellipse; label="test-user-2 (1)" ]`,
+		`user_test_2duser_2d3 [ shape=ellipse; label="test-user-3 (0)"; style=dashed ]`,
+		`user_test_2duser -> team_test_2dteam_2d4;`,
+		`user_test_2duser_2d2 -> team_test_2dteam [color=blue; label="★"];`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Membership matrix cells.
+const (
+	cellMember     = "member"
+	cellMaintainer = "maintainer"
+	cellInherited  = "inherited" // member of a child team, gets access via parent team
+)
+
+// writeMatrix writes membership matrix as CSV:
+// one row per organization member, one column per team
+// and the number of teams the member belongs to directly.
+func writeMatrix(w io.Writer, d data) error {
+	slugs := sortedKeys(d.Teams)
+	logins := allLogins(d)
+
+	children := childTeams(d.Teams)
+	cells := make(map[string]map[string]string, len(slugs))
+	for _, slug := range slugs {
+		cells[slug] = teamCells(d.Teams, children, slug)
+	}
+
+	cw := csv.NewWriter(w)
+
+	header := append([]string{"login"}, slugs...)
+	header = append(header, "teams")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, login := range logins {
+		row := make([]string, 0, len(slugs)+2)
+		row = append(row, login)
+
+		count := 0
+		for _, slug := range slugs {
+			cell := cells[slug][login]
+			if cell == cellMember || cell == cellMaintainer {
+				count++
+			}
+			row = append(row, cell)
+		}
+
+		row = append(row, strconv.Itoa(count))
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// allLogins returns sorted logins of organization members and team members,
+// including members without team.
+func allLogins(d data) []string {
+	set := toSet(d.Members)
+	for _, team := range d.Teams {
+		for _, member := range team.Members {
+			set[member.Login] = struct{}{}
+		}
+	}
+
+	logins := make([]string, 0, len(set))
+	for login := range set {
+		logins = append(logins, login)
+	}
+	sort.Slice(logins, func(i, j int) bool {
+		return strings.ToLower(logins[i]) < strings.ToLower(logins[j])
+	})
+
+	return logins
+}
+
+// teamCells returns matrix cells of the team by login.
+// GitHub lists members of child teams as members of the parent team,
+// so a member of any descendant team is marked as inherited,
+// unless they are the team maintainer.
+func teamCells(teams map[string]*Team, children map[string][]string, slug string) map[string]string {
+	inherited := map[string]struct{}{}
+	for _, child := range descendants(children, slug) {
+		for _, member := range teams[child].Members {
+			inherited[member.Login] = struct{}{}
+		}
+	}
+
+	cells := make(map[string]string, len(teams[slug].Members)+len(inherited))
+	for login := range inherited {
+		cells[login] = cellInherited
+	}
+
+	for _, member := range teams[slug].Members {
+		switch {
+		case member.IsMaintainer():
+			cells[member.Login] = cellMaintainer
+		case cells[member.Login] == "":
+			cells[member.Login] = cellMember
+		}
+	}
+
+	return cells
+}
+
+// childTeams returns slugs of child teams by parent team slug.
+func childTeams(teams map[string]*Team) map[string][]string {
+	children := map[string][]string{}
+	for slug, team := range teams {
+		if _, ok := teams[team.Parent]; ok {
+			children[team.Parent] = append(children[team.Parent], slug)
+		}
+	}
+
+	return children
+}
+
+// descendants returns slugs of all child teams of the team, recursively.
+func descendants(children map[string][]string, slug string) []string {
+	var result []string
+	seen := map[string]struct{}{slug: {}}
+	queue := []string{slug}
+	for len(queue) > 0 {
+		for _, child := range children[queue[0]] {
+			if _, ok := seen[child]; ok {
+				continue
+			}
+			seen[child] = struct{}{}
+			result = append(result, child)
+			queue = append(queue, child)
+		}
+		queue = queue[1:]
+	}
+
+	return result
+}
+
+// topLevelTeams returns sorted slugs of teams without a parent team,
+// followed by the first team of every parent cycle, which has no top-level ancestor.
+func topLevelTeams(teams map[string]*Team, children map[string][]string) []string {
+	var result []string
+	reached := map[string]struct{}{}
+	add := func(slug string) {
+		result = append(result, slug)
+		reached[slug] = struct{}{}
+		for _, descendant := range descendants(children, slug) {
+			reached[descendant] = struct{}{}
+		}
+	}
+
+	for _, slug := range sortedKeys(teams) {
+		if _, ok := teams[teams[slug].Parent]; !ok {
+			add(slug)
+		}
+	}
+	for _, slug := range sortedKeys(teams) {
+		if _, ok := reached[slug]; !ok {
+			add(slug)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestShouldWriteMembershipMatrix(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"parent":     {"Alice", "bob", "carol"},
+		"child":      {"bob", "carol"},
+		"grandchild": {"carol"},
+		"other":      {"alice-2"},
+	})
+	teams["parent"].Members[0].Role = RoleMaintainer
+	teams["child"].Parent = "parent"
+	teams["grandchild"].Parent = "child"
+
+	org := &Organization{
+		Login:   "test-org",
+		Teams:   teams,
+		Members: []string{"Alice", "alice-2", "bob", "carol", "dave"},
+	}
+
+	var b bytes.Buffer
+	if err := writeMatrix(&b, newData(org)); err != nil {
+		t.Fatalf("Error writing matrix: %v", err)
+	}
+
+	expected := `login,child,grandchild,other,parent,teams
+Alice,,,,maintainer,1
+alice-2,,,member,,1
+bob,member,,,inherited,1
+carol,inherited,member,,inherited,1
+dave,,,,,0
+`
+	if b.String() != expected {
+		t.Errorf("Expected matrix to be:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestShouldFindTopLevelTeams(t *testing.T) {
+	teams := map[string]*Team{
+		"a":   {Slug: "a"},
+		"a-1": {Slug: "a-1", Parent: "a"},
+		"b":   {Slug: "b", Parent: "ghost"},
+		"c":   {Slug: "c", Parent: "d"},
+		"d":   {Slug: "d", Parent: "c"},
+	}
+
+	expected := []string{"a", "b", "c"}
+	if got := topLevelTeams(teams, childTeams(teams)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected top-level teams to be %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+// Organization is a GitHub organization with its teams and members.
+type Organization struct {
+	ID      int64            `json:"id"`
+	Login   string           `json:"login"`
+	Teams   map[string]*Team `json:"teams"` // keyed by team slug
+	Members []string         `json:"members"`
+}
+
+// Team is a GitHub team.
+type Team struct {
+	ID          int64    `json:"id"`
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Privacy     string   `json:"privacy,omitempty"`
+	Parent      string   `json:"parent,omitempty"` // parent team slug, empty for top-level teams
+	Members     []Member `json:"members"`
+}
+
+// Team member roles.
+const (
+	RoleMember     = "member"
+	RoleMaintainer = "maintainer"
+)
+
+// Member is a member of a team.
+type Member struct {
+	Login string `json:"login"`
+	Role  string `json:"role"` // RoleMember or RoleMaintainer
+}
+
+// IsMaintainer reports whether the member is a team maintainer.
+func (m Member) IsMaintainer() bool {
+	return m.Role == RoleMaintainer
+}
+
+// Logins returns logins of team members.
+func (t *Team) Logins() []string {
+	logins := make([]string, 0, len(t.Members))
+	for _, member := range t.Members {
+		logins = append(logins, member.Login)
+	}
+
+	return logins
+}
+
+// Maintainers returns logins of team maintainers.
+func (t *Team) Maintainers() []string {
+	var logins []string
+	for _, member := range t.Members {
+		if member.IsMaintainer() {
+			logins = append(logins, member.Login)
+		}
+	}
+
+	return logins
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Network node and edge types.
+const (
+	nodeTeam = "team"
+	nodeUser = "user"
+
+	edgeMembership = "membership" // from user to team
+	edgeParent     = "parent"     // from parent team to child team
+	edgeSubset     = "subset"     // from team to team that contains all its members
+)
+
+// network is a graph of teams and users for network analysis tools.
+type network struct {
+	Nodes []networkNode
+	Edges []networkEdge
+}
+
+type networkNode struct {
+	ID    string
+	Type  string
+	Label string
+
+	// team attributes
+	Size    int
+	Privacy string
+	Depth   int
+}
+
+type networkEdge struct {
+	Source string
+	Target string
+	Type   string
+	Role   string // membership edges only
+}
+
+// newNetwork returns network of teams and users sorted by slug and login.
+func newNetwork(d data) network {
+	var n network
+
+	slugs := sortedKeys(d.Teams)
+	depths := teamDepths(d.Teams)
+	for _, slug := range slugs {
+		team := d.Teams[slug]
+		n.Nodes = append(n.Nodes, networkNode{
+			ID:      nodeID(slug),
+			Type:    nodeTeam,
+			Label:   team.Name,
+			Size:    len(team.Members),
+			Privacy: team.Privacy,
+			Depth:   depths[slug],
+		})
+	}
+
+	for _, login := range allLogins(d) {
+		n.Nodes = append(n.Nodes, networkNode{
+			ID:    userNodeID(login),
+			Type:  nodeUser,
+			Label: login,
+		})
+	}
+
+	for _, slug := range slugs {
+		team := d.Teams[slug]
+		for _, member := range team.Members {
+			n.Edges = append(n.Edges, networkEdge{
+				Source: userNodeID(member.Login),
+				Target: nodeID(slug),
+				Type:   edgeMembership,
+				Role:   member.Role,
+			})
+		}
+
+		if _, ok := d.Teams[team.Parent]; ok {
+			n.Edges = append(n.Edges, networkEdge{
+				Source: nodeID(team.Parent),
+				Target: nodeID(slug),
+				Type:   edgeParent,
+			})
+		}
+
+		supersets := d.Subsets.GetSubsets(slug)
+		sort.Strings(supersets)
+		for _, superset := range supersets {
+			n.Edges = append(n.Edges, networkEdge{
+				Source: nodeID(slug),
+				Target: nodeID(superset),
+				Type:   edgeSubset,
+			})
+		}
+	}
+
+	return n
+}
+
+// teamDepths returns nesting depth of teams by slug, 0 for top-level teams.
+func teamDepths(teams map[string]*Team) map[string]int {
+	depths := make(map[string]int, len(teams))
+
+	var depth func(slug string, seen map[string]struct{}) int
+	depth = func(slug string, seen map[string]struct{}) int {
+		if d, ok := depths[slug]; ok {
+			return d
+		}
+
+		parent := teams[slug].Parent
+		if _, ok := teams[parent]; !ok {
+			return 0
+		}
+		if _, ok := seen[parent]; ok {
+			return 0 // cycle
+		}
+		seen[slug] = struct{}{}
+
+		return depth(parent, seen) + 1
+	}
+
+	for slug := range teams {
+		depths[slug] = depth(slug, map[string]struct{}{})
+	}
+
+	return depths
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeGraphML writes teams and users network as GraphML.
+func writeGraphML(w io.Writer, d data) error {
+	n := newNetwork(d)
+
+	g := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "type", For: "all", Name: "type", Type: "string"},
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "size", For: "node", Name: "size", Type: "int"},
+			{ID: "privacy", For: "node", Name: "privacy", Type: "string"},
+			{ID: "depth", For: "node", Name: "depth", Type: "int"},
+			{ID: "role", For: "edge", Name: "role", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: d.Org.Login, EdgeDefault: "directed"},
+	}
+
+	for _, node := range n.Nodes {
+		gn := graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "type", Value: node.Type},
+				{Key: "label", Value: node.Label},
+			},
+		}
+		if node.Type == nodeTeam {
+			gn.Data = append(gn.Data,
+				graphMLData{Key: "size", Value: strconv.Itoa(node.Size)},
+				graphMLData{Key: "privacy", Value: node.Privacy},
+				graphMLData{Key: "depth", Value: strconv.Itoa(node.Depth)},
+			)
+		}
+		g.Graph.Nodes = append(g.Graph.Nodes, gn)
+	}
+
+	for i, edge := range n.Edges {
+		ge := graphMLEdge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: edge.Source,
+			Target: edge.Target,
+			Data:   []graphMLData{{Key: "type", Value: edge.Type}},
+		}
+		if edge.Role != "" {
+			ge.Data = append(ge.Data, graphMLData{Key: "role", Value: edge.Role})
+		}
+		g.Graph.Edges = append(g.Graph.Edges, ge)
+	}
+
+	return writeXML(w, g)
+}
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// writeGEXF writes teams and users network as GEXF.
+func writeGEXF(w io.Writer, d data) error {
+	n := newNetwork(d)
+
+	g := gexf{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: []gexfAttribute{
+					{ID: "type", Title: "type", Type: "string"},
+					{ID: "size", Title: "size", Type: "integer"},
+					{ID: "privacy", Title: "privacy", Type: "string"},
+					{ID: "depth", Title: "depth", Type: "integer"},
+				}},
+				{Class: "edge", Attributes: []gexfAttribute{
+					{ID: "type", Title: "type", Type: "string"},
+					{ID: "role", Title: "role", Type: "string"},
+				}},
+			},
+		},
+	}
+
+	for _, node := range n.Nodes {
+		gn := gexfNode{
+			ID:        node.ID,
+			Label:     node.Label,
+			AttValues: []gexfAttValue{{For: "type", Value: node.Type}},
+		}
+		if node.Type == nodeTeam {
+			gn.AttValues = append(gn.AttValues,
+				gexfAttValue{For: "size", Value: strconv.Itoa(node.Size)},
+				gexfAttValue{For: "privacy", Value: node.Privacy},
+				gexfAttValue{For: "depth", Value: strconv.Itoa(node.Depth)},
+			)
+		}
+		g.Graph.Nodes = append(g.Graph.Nodes, gn)
+	}
+
+	for i, edge := range n.Edges {
+		ge := gexfEdge{
+			ID:        strconv.Itoa(i),
+			Source:    edge.Source,
+			Target:    edge.Target,
+			Label:     edge.Type,
+			AttValues: []gexfAttValue{{For: "type", Value: edge.Type}},
+		}
+		if edge.Role != "" {
+			ge.AttValues = append(ge.AttValues, gexfAttValue{For: "role", Value: edge.Role})
+		}
+		g.Graph.Edges = append(g.Graph.Edges, ge)
+	}
+
+	return writeXML(w, g)
+}
+
+// writeXML writes v as indented XML document.
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
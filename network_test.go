@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestShouldBuildNetwork(t *testing.T) {
+	n := newNetwork(newData(newTestOrganization()))
+
+	var nodes []string
+	for _, node := range n.Nodes {
+		nodes = append(nodes, node.Type+":"+node.Label)
+	}
+
+	expectedNodes := []string{
+		`team:Test "Team" | <1> & co`,
+		"team:test-team-2",
+		"team:test-team-3",
+		"user:test-user",
+		"user:test-user-2",
+		"user:test-user-3",
+	}
+	if !reflect.DeepEqual(nodes, expectedNodes) {
+		t.Errorf("Expected nodes to be %v, got %v", expectedNodes, nodes)
+	}
+	if n.Nodes[1].Depth != 1 {
+		t.Errorf("Expected test-team-2 depth to be 1, got %d", n.Nodes[1].Depth)
+	}
+
+	var edges []string
+	for _, edge := range n.Edges {
+		edges = append(edges, edge.Type+":"+edge.Source+">"+edge.Target)
+	}
+
+	expectedEdges := []string{
+		"membership:user_test_2duser>team_test_2dteam",
+		"membership:user_test_2duser_2d2>team_test_2dteam",
+		"membership:user_test_2duser>team_test_2dteam_2d2",
+		"parent:team_test_2dteam>team_test_2dteam_2d2",
+		"subset:team_test_2dteam_2d2>team_test_2dteam",
+		"membership:user_test_2duser_2d2>team_test_2dteam_2d3",
+		"subset:team_test_2dteam_2d3>team_test_2dteam",
+	}
+	if !reflect.DeepEqual(edges, expectedEdges) {
+		t.Errorf("Expected edges to be %v, got %v", expectedEdges, edges)
+	}
+}
+
+func TestShouldWriteGraphML(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeGraphML(&b, newData(newTestOrganization())); err != nil {
+		t.Fatalf("Error writing GraphML: %v", err)
+	}
+
+	var g graphML
+	if err := xml.Unmarshal(b.Bytes(), &g); err != nil {
+		t.Fatalf("Error decoding GraphML: %v\n%s", err, b.String())
+	}
+
+	if len(g.Graph.Nodes) != 6 || len(g.Graph.Edges) != 7 {
+		t.Errorf("Expected 6 nodes and 7 edges, got %d and %d", len(g.Graph.Nodes), len(g.Graph.Edges))
+	}
+	if label := g.Graph.Nodes[0].Data[1].Value; label != `Test "Team" | <1> & co` {
+		t.Errorf("Expected team label to be decoded, got %q", label)
+	}
+}
+
+func TestShouldWriteGEXF(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeGEXF(&b, newData(newTestOrganization())); err != nil {
+		t.Fatalf("Error writing GEXF: %v", err)
+	}
+
+	var g gexf
+	if err := xml.Unmarshal(b.Bytes(), &g); err != nil {
+		t.Fatalf("Error decoding GEXF: %v\n%s", err, b.String())
+	}
+
+	if len(g.Graph.Nodes) != 6 || len(g.Graph.Edges) != 7 {
+		t.Errorf("Expected 6 nodes and 7 edges, got %d and %d", len(g.Graph.Nodes), len(g.Graph.Edges))
+	}
+	if label := g.Graph.Nodes[0].Label; label != `Test "Team" | <1> & co` {
+		t.Errorf("Expected team label to be decoded, got %q", label)
+	}
+	if role := g.Graph.Edges[0].AttValues[1].Value; role != RoleMaintainer {
+		t.Errorf("Expected first membership role to be maintainer, got %q", role)
+	}
+}
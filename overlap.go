@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Similarity measures of team members.
+const (
+	similarityJaccard = "jaccard" // shared members divided by members of both teams
+	similarityOverlap = "overlap" // shared members divided by members of the smaller team
+)
+
+// teamOverlap is a pair of teams sharing members.
+type teamOverlap struct {
+	Team       string  `json:"team"`
+	OtherTeam  string  `json:"other_team"`
+	Shared     int     `json:"shared"` // number of members in both teams
+	Jaccard    float64 `json:"jaccard"`
+	Overlap    float64 `json:"overlap"`
+	Similarity float64 `json:"similarity"` // by the measure used to find overlaps
+	Subset     bool    `json:"subset"`     // one team has all members of the other
+}
+
+// PenWidth returns width of the overlap edge in dot format.
+func (o teamOverlap) PenWidth() string {
+	return fmt.Sprintf("%.1f", 3*o.Similarity)
+}
+
+// FindOverlaps returns pairs of teams with similarity of members at least threshold,
+// most similar first. Measure is either similarityJaccard or similarityOverlap,
+// threshold is greater than 0 and at most 1.
+//
+// Shared members are counted only for teams that have a member in common,
+// using teams of every member, so unrelated pairs are never compared.
+func FindOverlaps(teams map[string]*Team, measure string, threshold float64) ([]teamOverlap, error) {
+	if err := checkSimilarity(measure, threshold); err != nil {
+		return nil, err
+	}
+
+	slugs := sortedKeys(teams)
+	members := make([][]string, len(slugs))
+	memberTeams := map[string][]int{} // team indexes by login, ascending
+	for i, slug := range slugs {
+		members[i] = sortedSetKeys(toSet(teams[slug].Logins()))
+		for _, login := range members[i] {
+			memberTeams[login] = append(memberTeams[login], i)
+		}
+	}
+
+	var overlaps []teamOverlap
+	shared := make([]int, len(slugs))
+	for i := range slugs {
+		var others []int
+		for _, login := range members[i] {
+			for _, j := range memberTeams[login] {
+				if j <= i {
+					continue
+				}
+				if shared[j] == 0 {
+					others = append(others, j)
+				}
+				shared[j]++
+			}
+		}
+
+		for _, j := range others {
+			n := shared[j]
+			shared[j] = 0
+
+			smaller := len(members[i])
+			if len(members[j]) < smaller {
+				smaller = len(members[j])
+			}
+
+			o := teamOverlap{
+				Team:      slugs[i],
+				OtherTeam: slugs[j],
+				Shared:    n,
+				Jaccard:   float64(n) / float64(len(members[i])+len(members[j])-n),
+				Overlap:   float64(n) / float64(smaller),
+				Subset:    n == smaller,
+			}
+			o.Similarity = o.Jaccard
+			if measure == similarityOverlap {
+				o.Similarity = o.Overlap
+			}
+
+			if o.Similarity >= threshold {
+				overlaps = append(overlaps, o)
+			}
+		}
+	}
+
+	sort.Slice(overlaps, func(i, j int) bool {
+		if overlaps[i].Similarity != overlaps[j].Similarity {
+			return overlaps[i].Similarity > overlaps[j].Similarity
+		}
+		if overlaps[i].Team != overlaps[j].Team {
+			return overlaps[i].Team < overlaps[j].Team
+		}
+		return overlaps[i].OtherTeam < overlaps[j].OtherTeam
+	})
+
+	return overlaps, nil
+}
+
+func sortedSetKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// checkSimilarity returns error if measure or threshold cannot be used by FindOverlaps.
+func checkSimilarity(measure string, threshold float64) error {
+	if measure != similarityJaccard && measure != similarityOverlap {
+		return fmt.Errorf("unknown similarity measure %q", measure)
+	}
+	if threshold <= 0 || threshold > 1 {
+		return fmt.Errorf("similarity threshold %v is out of range, expected greater than 0 and at most 1", threshold)
+	}
+
+	return nil
+}
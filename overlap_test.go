@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShouldFindOverlaps(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a": {"test-user", "test-user-2", "test-user-3", "test-user-4", "test-user-5"},
+		"b": {"test-user", "test-user-2", "test-user-3", "test-user-4", "test-user-6"},
+		"c": {"test-user", "test-user-2"},
+		"d": {"test-user-7"},
+		"e": {},
+	})
+
+	overlaps, err := FindOverlaps(teams, similarityJaccard, 0.4)
+	if err != nil {
+		t.Fatalf("Error finding overlaps: %v", err)
+	}
+
+	expected := []teamOverlap{
+		{Team: "a", OtherTeam: "b", Shared: 4, Jaccard: 4.0 / 6, Overlap: 0.8, Similarity: 4.0 / 6},
+		{Team: "a", OtherTeam: "c", Shared: 2, Jaccard: 0.4, Overlap: 1, Similarity: 0.4, Subset: true},
+		{Team: "b", OtherTeam: "c", Shared: 2, Jaccard: 0.4, Overlap: 1, Similarity: 0.4, Subset: true},
+	}
+	if !reflect.DeepEqual(overlaps, expected) {
+		t.Errorf("Expected overlaps to be %v, got %v", expected, overlaps)
+	}
+}
+
+func TestShouldFindOverlapsByOverlapCoefficient(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a": {"test-user", "test-user-2", "test-user-3", "test-user-4", "test-user-5"},
+		"b": {"test-user", "test-user-2", "test-user-3", "test-user-4", "test-user-6"},
+		"c": {"test-user", "test-user-2"},
+	})
+
+	overlaps, err := FindOverlaps(teams, similarityOverlap, 0.8)
+	if err != nil {
+		t.Fatalf("Error finding overlaps: %v", err)
+	}
+
+	var pairs []string
+	for _, o := range overlaps {
+		pairs = append(pairs, o.Team+"-"+o.OtherTeam)
+	}
+
+	expected := []string{"a-c", "b-c", "a-b"}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected overlapping teams to be %v, got %v", expected, pairs)
+	}
+}
+
+func TestShouldFailOnUnknownSimilarity(t *testing.T) {
+	if _, err := FindOverlaps(nil, "cosine", 0.5); err == nil {
+		t.Error("Expected error for unknown similarity measure")
+	}
+}
+
+func TestShouldFailOnSimilarityThresholdOutOfRange(t *testing.T) {
+	for _, threshold := range []float64{0, -0.5, 1.5} {
+		if _, err := FindOverlaps(nil, similarityJaccard, threshold); err == nil {
+			t.Errorf("Expected error for similarity threshold %v", threshold)
+		}
+	}
+
+	if _, err := FindOverlaps(nil, similarityJaccard, 1); err != nil {
+		t.Errorf("Expected no error for similarity threshold 1, got %v", err)
+	}
+}
+
+func TestShouldFindOverlapsForFormatsThatUseThem(t *testing.T) {
+	tests := []struct {
+		cfg      config
+		expected bool
+	}{
+		{config{Format: "report"}, true},
+		{config{Format: "json"}, true},
+		{config{Format: "dot"}, false},
+		{config{Format: "dot", SimilarityEdges: true}, true},
+		{config{Format: "terraform", SimilarityEdges: true}, false},
+		{config{Format: "csv", Template: "custom.tmpl"}, true},
+	}
+
+	for _, test := range tests {
+		if got := test.cfg.usesOverlaps(); got != test.expected {
+			t.Errorf("Expected usesOverlaps to be %v for %+v, got %v", test.expected, test.cfg, got)
+		}
+	}
+}
+
+func TestShouldRenderOverlaps(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a": {"test-user", "test-user-2", "test-user-3", "test-user-4", "test-user-5"},
+		"b": {"test-user", "test-user-2", "test-user-3", "test-user-4", "test-user-6"},
+	})
+
+	d := newData(&Organization{Login: "test-org", Teams: teams})
+	d.Similarity, d.SimilarityThreshold, d.SimilarityEdges = similarityJaccard, 0.5, true
+
+	var err error
+	d.Overlaps, err = FindOverlaps(d.Teams, d.Similarity, d.SimilarityThreshold)
+	if err != nil {
+		t.Fatalf("Error finding overlaps: %v", err)
+	}
+
+	for tmpl, expected := range map[string][]string{
+		"dot.tmpl": {
+			`"a" -> "b" [style=dotted; dir=none; constraint=false; penwidth=2.0; label="0.67"];`,
+		},
+		"dot-members.tmpl": {
+			`team_a -> team_b [style=dotted; dir=none; constraint=false; penwidth=2.0; label="0.67"];`,
+		},
+		"dot-clusters.tmpl": {
+			`"a" -> "b" [style=dotted; dir=none; constraint=false; penwidth=2.0; label="0.67"];`,
+		},
+		"report.tmpl": {
+			"Teams with jaccard similarity of members at least 0.50, most similar first.",
+			"| [a](#a) | [b](#b) | 0.67 | 4 | no |",
+		},
+	} {
+		output := filepath.Join(t.TempDir(), "output")
+		if err := renderTemplate("", builtinTemplate(tmpl), output, d); err != nil {
+			t.Fatalf("Error rendering template %s: %v", tmpl, err)
+		}
+
+		b, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("Error reading output: %v", err)
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(string(b), e) {
+				t.Errorf("Expected %s output to contain %q, got:\n%s", tmpl, e, b)
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/google/go-github/v48/github"
 )
@@ -25,7 +26,9 @@ type Processor struct {
 	Context              context.Context
 	OrganizationsService organizationsService
 	TeamsService         teamsService
+	GraphQLService       graphqlService
 	HideMembers          bool
+	Concurrency          int
 }
 
 func (p *Processor) GetOrganizationID(orgName string) (int64, error) {
@@ -70,37 +73,109 @@ func (p *Processor) Members(orgName string) ([]string, error) {
 	return result, nil
 }
 
-func (p *Processor) Teams(orgName string, orgID int64) (teamMembers map[string][]string, teamParents map[string]string, err error) {
+// Teams returns organization teams keyed by slug.
+func (p *Processor) Teams(orgName string, orgID int64) (map[string]*Team, error) {
+	if p.GraphQLService != nil {
+		return p.teamsGraphQL(orgName)
+	}
+
 	teams, err := p.getTeamsPaginated(orgName)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	teamMembers = make(map[string][]string)
-	teamParents = make(map[string]string)
-	for _, team := range teams {
-		if !p.HideMembers {
-			members, err := p.getTeamMembersPaginated(orgID, team)
-			if err != nil {
-				return nil, nil, fmt.Errorf("failed to list team members for %q: %w", *team.Name, err)
-			}
+	var members [][]Member
+	if !p.HideMembers {
+		members, err = p.getTeamsMembers(orgID, teams)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-			var returnMembers []string
-			for _, member := range members {
-				returnMembers = append(returnMembers, *member.Login)
-			}
+	result := make(map[string]*Team, len(teams))
+	for i, team := range teams {
+		t := &Team{
+			ID:          team.GetID(),
+			Slug:        team.GetSlug(),
+			Name:        team.GetName(),
+			Description: team.GetDescription(),
+			Privacy:     team.GetPrivacy(),
+		}
 
-			sort.Slice(returnMembers, func(i, j int) bool { return strings.ToLower(returnMembers[i]) < strings.ToLower(returnMembers[j]) })
+		if team.Parent != nil {
+			t.Parent = team.Parent.GetSlug()
+		}
 
-			teamMembers[*team.Name] = returnMembers
+		if !p.HideMembers {
+			t.Members = members[i]
 		}
 
-		if team.Parent != nil {
-			teamParents[*team.Name] = *team.Parent.Name
+		result[t.Slug] = t
+	}
+
+	return result, nil
+}
+
+// getTeamsMembers returns sorted members for each of teams, in the same order.
+// Up to p.Concurrency teams are fetched in parallel,
+// the first error cancels requests that are still in flight.
+func (p *Processor) getTeamsMembers(orgID int64, teams []*github.Team) ([][]Member, error) {
+	ctx, cancel := context.WithCancel(p.Context)
+	defer cancel()
+
+	workers := p.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		result   = make([][]Member, len(teams))
+		jobs     = make(chan int)
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				members, err := p.getTeamMembers(ctx, orgID, teams[i])
+				if err != nil {
+					once.Do(func() {
+						firstErr = fmt.Errorf("failed to list team members for %q: %w", teams[i].GetName(), err)
+						cancel()
+					})
+					continue
+				}
+
+				result[i] = members
+			}
+		}()
+	}
+
+loop:
+	for i := range teams {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
 		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
 
-	return teamMembers, teamParents, nil
+	if err := p.Context.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func (p *Processor) getTeamsPaginated(orgName string) ([]*github.Team, error) {
@@ -132,16 +207,51 @@ func (p *Processor) getTeamsPaginated(orgName string) ([]*github.Team, error) {
 	return allTeams, nil
 }
 
-func (p *Processor) getTeamMembersPaginated(orgID int64, team *github.Team) ([]*github.User, error) {
+// getTeamMembers returns sorted team members with their roles.
+func (p *Processor) getTeamMembers(ctx context.Context, orgID int64, team *github.Team) ([]Member, error) {
+	maintainers, err := p.getTeamMembersPaginated(ctx, orgID, team, RoleMaintainer)
+	if err != nil {
+		return nil, err
+	}
+
+	isMaintainer := make(map[string]bool, len(maintainers))
+	for _, maintainer := range maintainers {
+		isMaintainer[maintainer.GetLogin()] = true
+	}
+
+	users, err := p.getTeamMembersPaginated(ctx, orgID, team, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	for _, user := range users {
+		role := RoleMember
+		if isMaintainer[user.GetLogin()] {
+			role = RoleMaintainer
+		}
+
+		members = append(members, Member{Login: user.GetLogin(), Role: role})
+	}
+
+	sortMembers(members)
+
+	return members, nil
+}
+
+// getTeamMembersPaginated returns team members with given role,
+// or all team members if role is empty.
+func (p *Processor) getTeamMembersPaginated(ctx context.Context, orgID int64, team *github.Team, role string) ([]*github.User, error) {
 	currentPage := 0
 
 	var allMembers []*github.User
 	for {
 		members, response, err := p.TeamsService.ListTeamMembersByID(
-			p.Context,
+			ctx,
 			orgID,
 			*team.ID,
 			&github.TeamListTeamMembersOptions{
+				Role: role,
 				ListOptions: github.ListOptions{
 					Page:    currentPage,
 					PerPage: perPage,
@@ -163,3 +273,7 @@ func (p *Processor) getTeamMembersPaginated(orgID int64, team *github.Team) ([]*
 
 	return allMembers, nil
 }
+
+func sortMembers(members []Member) {
+	sort.Slice(members, func(i, j int) bool { return strings.ToLower(members[i].Login) < strings.ToLower(members[j].Login) })
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v48/github"
 	"github.com/stretchr/testify/mock"
@@ -38,6 +40,23 @@ func (m *mockTeamsService) ListTeamMembersByID(ctx context.Context, orgID, teamI
 	return args.Get(0).([]*github.User), args.Get(1).(*github.Response), args.Error(2)
 }
 
+// withRole matches team members options with given role.
+func withRole(role string) interface{} {
+	return mock.MatchedBy(func(opts *github.TeamListTeamMembersOptions) bool {
+		return opts.Role == role
+	})
+}
+
+// teamLogins returns member logins keyed by team slug.
+func teamLogins(teams map[string]*Team) map[string][]string {
+	result := make(map[string][]string, len(teams))
+	for slug, team := range teams {
+		result[slug] = team.Logins()
+	}
+
+	return result
+}
+
 func TestShoudCheckOrganizationAccess(t *testing.T) {
 	mockOS := new(mockOrganizationsService)
 	mockOS.On("Get", mock.Anything, "test-org").Return(&github.Organization{ID: github.Int64(123)}, &github.Response{}, nil)
@@ -157,7 +176,7 @@ func TestShoudListMembersPaginated(t *testing.T) {
 				{Login: github.String("test-user-6")},
 			},
 			&github.Response{
-				NextPage: 2,
+				NextPage: 0,
 				LastPage: 2,
 			},
 			nil,
@@ -190,14 +209,18 @@ func TestShoudListMembersPaginated(t *testing.T) {
 func TestShouldGetTeams(t *testing.T) {
 	mockTS := new(mockTeamsService)
 	mockTS.On("ListTeams", mock.Anything, "test-org", mock.Anything).Return([]*github.Team{
-		{ID: github.Int64(1), Name: github.String("test-team")},
-		{ID: github.Int64(2), Name: github.String("test-team-2"), Parent: &github.Team{ID: github.Int64(1), Name: github.String("test-team")}},
+		{ID: github.Int64(1), Name: github.String("test-team"), Slug: github.String("test-team")},
+		{ID: github.Int64(2), Name: github.String("test-team-2"), Slug: github.String("test-team-2"), Parent: &github.Team{ID: github.Int64(1), Name: github.String("test-team"), Slug: github.String("test-team")}},
 	}, &github.Response{}, nil)
 	mockTS.On("ListTeams", mock.Anything, "bad-org", mock.Anything).Return([]*github.Team{}, &github.Response{}, fmt.Errorf("error"))
 	mockTS.On("ListTeams", mock.Anything, "bad-org-2", mock.Anything).Return([]*github.Team{
-		{ID: github.Int64(3), Name: github.String("test-team")},
+		{ID: github.Int64(3), Name: github.String("test-team"), Slug: github.String("test-team")},
 	}, &github.Response{}, nil)
 
+	mockTS.On("ListTeamMembersByID", mock.Anything, int64(123), int64(1), withRole("maintainer")).Return([]*github.User{
+		{Login: github.String("test-user-2")},
+	}, &github.Response{}, nil)
+	mockTS.On("ListTeamMembersByID", mock.Anything, int64(123), int64(2), withRole("maintainer")).Return([]*github.User{}, &github.Response{}, nil)
 	mockTS.On("ListTeamMembersByID", mock.Anything, int64(123), int64(1), mock.Anything).Return([]*github.User{
 		{Login: github.String("test-user")},
 		{Login: github.String("test-user-2")},
@@ -213,34 +236,37 @@ func TestShouldGetTeams(t *testing.T) {
 		TeamsService: mockTS,
 	}
 
-	teams, parents, err := processor.Teams("test-org", 123)
+	teams, err := processor.Teams("test-org", 123)
 	if err != nil {
 		t.Errorf("Error creating processor: %v", err)
 	}
 
-	expected := map[string][]string{
-		"test-team":   {"test-user", "test-user-2"},
-		"test-team-2": {"test-user-3", "test-user-4"},
+	expected := map[string]*Team{
+		"test-team": {
+			ID:      1,
+			Slug:    "test-team",
+			Name:    "test-team",
+			Members: []Member{{Login: "test-user", Role: "member"}, {Login: "test-user-2", Role: "maintainer"}},
+		},
+		"test-team-2": {
+			ID:      2,
+			Slug:    "test-team-2",
+			Name:    "test-team-2",
+			Parent:  "test-team",
+			Members: []Member{{Login: "test-user-3", Role: "member"}, {Login: "test-user-4", Role: "member"}},
+		},
 	}
 
 	if !reflect.DeepEqual(teams, expected) {
 		t.Errorf("Expected teams to be %v, got %v", expected, teams)
 	}
 
-	expectedParents := map[string]string{
-		"test-team-2": "test-team",
-	}
-
-	if !reflect.DeepEqual(parents, expectedParents) {
-		t.Errorf("Expected parents to be %v, got %v", expectedParents, parents)
-	}
-
-	_, _, err = processor.Teams("bad-org", 124)
+	_, err = processor.Teams("bad-org", 124)
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
 
-	_, _, err = processor.Teams("bad-org-2", 125)
+	_, err = processor.Teams("bad-org-2", 125)
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
@@ -260,8 +286,8 @@ func TestShouldGetTeamsPaginated(t *testing.T) {
 		).
 		Return(
 			[]*github.Team{
-				{ID: github.Int64(1), Name: github.String("test-team")},
-				{ID: github.Int64(2), Name: github.String("test-team-2")},
+				{ID: github.Int64(1), Name: github.String("test-team"), Slug: github.String("test-team")},
+				{ID: github.Int64(2), Name: github.String("test-team-2"), Slug: github.String("test-team-2")},
 			},
 			&github.Response{
 				NextPage: 1,
@@ -281,8 +307,8 @@ func TestShouldGetTeamsPaginated(t *testing.T) {
 		).
 		Return(
 			[]*github.Team{
-				{ID: github.Int64(1), Name: github.String("test-team-3")},
-				{ID: github.Int64(2), Name: github.String("test-team-4")},
+				{ID: github.Int64(1), Name: github.String("test-team-3"), Slug: github.String("test-team-3")},
+				{ID: github.Int64(2), Name: github.String("test-team-4"), Slug: github.String("test-team-4")},
 			},
 			&github.Response{
 				NextPage: 2,
@@ -302,11 +328,11 @@ func TestShouldGetTeamsPaginated(t *testing.T) {
 		).
 		Return(
 			[]*github.Team{
-				{ID: github.Int64(1), Name: github.String("test-team-5")},
-				{ID: github.Int64(2), Name: github.String("test-team-6")},
+				{ID: github.Int64(1), Name: github.String("test-team-5"), Slug: github.String("test-team-5")},
+				{ID: github.Int64(2), Name: github.String("test-team-6"), Slug: github.String("test-team-6")},
 			},
 			&github.Response{
-				NextPage: 2,
+				NextPage: 0,
 				LastPage: 2,
 			},
 			nil,
@@ -322,7 +348,7 @@ func TestShouldGetTeamsPaginated(t *testing.T) {
 		TeamsService: mockTS,
 	}
 
-	teams, _, err := processor.Teams("test-org", 123)
+	teams, err := processor.Teams("test-org", 123)
 	if err != nil {
 		t.Errorf("Error creating processor: %v", err)
 	}
@@ -336,8 +362,8 @@ func TestShouldGetTeamsPaginated(t *testing.T) {
 		"test-team-6": {"test-user"},
 	}
 
-	if !reflect.DeepEqual(teams, expected) {
-		t.Errorf("Expected teams to be %v, got %v", expected, teams)
+	if !reflect.DeepEqual(teamLogins(teams), expected) {
+		t.Errorf("Expected teams to be %v, got %v", expected, teamLogins(teams))
 	}
 }
 
@@ -345,7 +371,10 @@ func TestShouldGetTeamsMembersPaginated(t *testing.T) {
 	mockTS := new(mockTeamsService)
 	mockTS.
 		On("ListTeams", mock.Anything, "test-org", mock.Anything).
-		Return([]*github.Team{{ID: github.Int64(1), Name: github.String("test-team")}}, &github.Response{}, nil)
+		Return([]*github.Team{{ID: github.Int64(1), Name: github.String("test-team"), Slug: github.String("test-team")}}, &github.Response{}, nil)
+	mockTS.
+		On("ListTeamMembersByID", mock.Anything, int64(123), mock.Anything, withRole("maintainer")).
+		Return([]*github.User{}, &github.Response{}, nil)
 
 	mockTS.
 		On(
@@ -411,7 +440,7 @@ func TestShouldGetTeamsMembersPaginated(t *testing.T) {
 				{Login: github.String("test-user-3")},
 			},
 			&github.Response{
-				NextPage: 2,
+				NextPage: 0,
 				LastPage: 2,
 			},
 			nil,
@@ -422,7 +451,7 @@ func TestShouldGetTeamsMembersPaginated(t *testing.T) {
 		TeamsService: mockTS,
 	}
 
-	teams, _, err := processor.Teams("test-org", 123)
+	teams, err := processor.Teams("test-org", 123)
 	if err != nil {
 		t.Errorf("Error creating processor: %v", err)
 	}
@@ -431,7 +460,103 @@ func TestShouldGetTeamsMembersPaginated(t *testing.T) {
 		"test-team": {"test-user", "test-user-2", "test-user-3"},
 	}
 
-	if !reflect.DeepEqual(teams, expected) {
-		t.Errorf("Expected teams to be %v, got %v", expected, teams)
+	if !reflect.DeepEqual(teamLogins(teams), expected) {
+		t.Errorf("Expected teams to be %v, got %v", expected, teamLogins(teams))
+	}
+}
+
+func TestShouldGetTeamsConcurrently(t *testing.T) {
+	const concurrency = 4
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+		full     = make(chan struct{})
+		fullOnce sync.Once
+	)
+	// the first calls wait until concurrency calls are in flight,
+	// so a serial implementation never gets past peak of 1
+	wait := func(mock.Arguments) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		if inFlight == concurrency {
+			fullOnce.Do(func() { close(full) })
+		}
+		mu.Unlock()
+
+		select {
+		case <-full:
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}
+
+	mockTS := new(mockTeamsService)
+
+	var teams []*github.Team
+	expected := map[string][]string{}
+	for i := 1; i <= 20; i++ {
+		name := fmt.Sprintf("test-team-%d", i)
+		teams = append(teams, &github.Team{ID: github.Int64(int64(i)), Name: github.String(name), Slug: github.String(name)})
+		expected[name] = []string{fmt.Sprintf("test-user-%d", i)}
+
+		mockTS.On("ListTeamMembersByID", mock.Anything, int64(123), int64(i), mock.Anything).Run(wait).Return([]*github.User{
+			{Login: github.String(fmt.Sprintf("test-user-%d", i))},
+		}, &github.Response{}, nil)
+	}
+	mockTS.On("ListTeams", mock.Anything, "test-org", mock.Anything).Return(teams, &github.Response{}, nil)
+
+	processor := Processor{
+		Context:      context.Background(),
+		TeamsService: mockTS,
+		Concurrency:  concurrency,
+	}
+
+	result, err := processor.Teams("test-org", 123)
+	if err != nil {
+		t.Errorf("Error getting teams: %v", err)
+	}
+
+	if !reflect.DeepEqual(teamLogins(result), expected) {
+		t.Errorf("Expected teams to be %v, got %v", expected, teamLogins(result))
+	}
+
+	if peak != concurrency {
+		t.Errorf("Expected %d team members requests in flight, got at most %d", concurrency, peak)
+	}
+}
+
+func TestShouldCancelTeamsOnFirstError(t *testing.T) {
+	mockTS := new(mockTeamsService)
+	mockTS.On("ListTeams", mock.Anything, "test-org", mock.Anything).Return([]*github.Team{
+		{ID: github.Int64(1), Name: github.String("test-team"), Slug: github.String("test-team")},
+		{ID: github.Int64(2), Name: github.String("test-team-2"), Slug: github.String("test-team-2")},
+	}, &github.Response{}, nil)
+
+	// second team blocks until the request is cancelled
+	mockTS.On("ListTeamMembersByID", mock.Anything, int64(123), int64(2), mock.Anything).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return([]*github.User{}, &github.Response{}, context.Canceled)
+	mockTS.On("ListTeamMembersByID", mock.Anything, int64(123), int64(1), mock.Anything).
+		Return([]*github.User{}, &github.Response{}, fmt.Errorf("error"))
+
+	processor := Processor{
+		Context:      context.Background(),
+		TeamsService: mockTS,
+		Concurrency:  2,
+	}
+
+	_, err := processor.Teams("test-org", 123)
+	if err == nil || err.Error() != `failed to list team members for "test-team": error` {
+		t.Errorf("Expected error for test-team, got %v", err)
 	}
 }
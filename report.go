@@ -0,0 +1,85 @@
+package main
+
+import "sort"
+
+// Children returns sorted slugs of child teams of the team.
+func (d data) Children(slug string) []string {
+	var children []string
+	for childSlug, team := range d.Teams {
+		if team.Parent == slug {
+			children = append(children, childSlug)
+		}
+	}
+	sort.Strings(children)
+
+	return children
+}
+
+// SubsetOf returns sorted slugs of teams that have all members of the team.
+func (d data) SubsetOf(slug string) []string {
+	teams := d.Subsets.GetSubsets(slug)
+	sort.Strings(teams)
+
+	return teams
+}
+
+// subsetEdge is a covering subset relation drawn in diagrams.
+type subsetEdge struct {
+	From string // slug of superset team, or first team of the group
+	To   string // slug of subset team
+	Same bool   // teams have identical members
+}
+
+// SubsetEdges returns ReducedSubsets, and SubsetGroups as edges from the first team
+// of the group to the others, skipping relations already drawn as parent edges.
+func (d data) SubsetEdges() []subsetEdge {
+	var edges []subsetEdge
+	isParent := func(from, to string) bool {
+		return d.Teams[to] != nil && d.Teams[to].Parent == from
+	}
+
+	for _, slug := range sortedSubsetKeys(d.ReducedSubsets) {
+		supersets := d.ReducedSubsets.GetSubsets(slug)
+		sort.Strings(supersets)
+		for _, superset := range supersets {
+			if !isParent(superset, slug) {
+				edges = append(edges, subsetEdge{From: superset, To: slug})
+			}
+		}
+	}
+
+	for _, group := range d.SubsetGroups {
+		for _, slug := range group[1:] {
+			if !isParent(group[0], slug) && !isParent(slug, group[0]) {
+				edges = append(edges, subsetEdge{From: group[0], To: slug, Same: true})
+			}
+		}
+	}
+
+	return edges
+}
+
+// EmptyTeams returns sorted slugs of teams without members.
+func (d data) EmptyTeams() []string {
+	var teams []string
+	for slug, team := range d.Teams {
+		if len(team.Members) == 0 {
+			teams = append(teams, slug)
+		}
+	}
+	sort.Strings(teams)
+
+	return teams
+}
+
+// MaxDepth returns maximum nesting depth of teams, 0 if there are no nested teams.
+func (d data) MaxDepth() int {
+	maxDepth := 0
+	for _, depth := range teamDepths(d.Teams) {
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+
+	return maxDepth
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShouldSummarizeTeams(t *testing.T) {
+	org := newTestOrganization()
+	org.Teams["test-team-3"].Parent = "test-team-2"
+	org.Teams["test-team-4"] = &Team{Slug: "test-team-4", Name: "test-team-4", Parent: "test-team"}
+	d := newData(org)
+
+	if got := d.Children("test-team"); !reflect.DeepEqual(got, []string{"test-team-2", "test-team-4"}) {
+		t.Errorf("Expected test-team children to be [test-team-2 test-team-4], got %v", got)
+	}
+	if got := d.SubsetOf("test-team-3"); !reflect.DeepEqual(got, []string{"test-team"}) {
+		t.Errorf("Expected test-team-3 to be subset of [test-team], got %v", got)
+	}
+	if got := d.EmptyTeams(); !reflect.DeepEqual(got, []string{"test-team-4"}) {
+		t.Errorf("Expected empty teams to be [test-team-4], got %v", got)
+	}
+	if got := d.MaxDepth(); got != 2 {
+		t.Errorf("Expected max depth to be 2, got %d", got)
+	}
+}
+
+func TestShouldRenderReport(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.md")
+	if err := renderTemplate("", builtinTemplate("report.tmpl"), output, newData(newTestOrganization())); err != nil {
+		t.Fatalf("Error rendering template: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+
+	for _, expected := range []string{
+		"| Teams                 | 3 |",
+		"| Members without team  | 1 |",
+		"| Maximum nesting depth | 1 |",
+		"## Test \"Team\" \\| &lt;1&gt; & co",
+		"- Parent: [Test \"Team\" \\| &lt;1&gt; & co](#test-team)",
+		"- Children: [test-team-2](#test-team-2)",
+		"- Subset of: [Test \"Team\" \\| &lt;1&gt; & co](#test-team)",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
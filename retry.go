@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+const (
+	defaultBaseDelay = time.Second
+	defaultMaxDelay  = time.Minute
+)
+
+// retrier retries GitHub API calls that failed because of rate limits
+// or transient server errors.
+type retrier struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	sleep  func(ctx context.Context, d time.Duration) error
+	now    func() time.Time
+	jitter func(d time.Duration) time.Duration
+
+	mu          sync.Mutex
+	quotaLogged bool
+	lastQuota   github.Rate
+}
+
+func newRetrier(maxRetries int) *retrier {
+	return &retrier{
+		MaxRetries: maxRetries,
+		BaseDelay:  defaultBaseDelay,
+		MaxDelay:   defaultMaxDelay,
+		sleep:      sleepContext,
+		now:        time.Now,
+		jitter:     fullJitter,
+	}
+}
+
+// retry calls fn until it succeeds, fails with non-retryable error
+// or r.MaxRetries retries are exhausted.
+func retry[T any](ctx context.Context, r *retrier, fn func() (T, *github.Response, error)) (T, *github.Response, error) {
+	for attempt := 0; ; attempt++ {
+		result, response, err := fn()
+		r.logQuota(response)
+
+		if err == nil || attempt >= r.MaxRetries {
+			return result, response, err
+		}
+
+		delay, ok := r.delay(err, attempt)
+		if !ok {
+			return result, response, err
+		}
+
+		log.Printf("GitHub API request failed (%v), retrying in %s...", err, delay.Round(time.Second))
+		if err := r.sleep(ctx, delay); err != nil {
+			return result, response, err
+		}
+	}
+}
+
+// delay returns how long to wait before retrying a call that failed with err,
+// or false if the call should not be retried.
+func (r *retrier) delay(err error, attempt int) (time.Duration, bool) {
+	var (
+		rateLimitErr      *github.RateLimitError
+		abuseRateLimitErr *github.AbuseRateLimitError
+		errorResponse     *github.ErrorResponse
+	)
+
+	switch {
+	case errors.As(err, &rateLimitErr):
+		delay := rateLimitErr.Rate.Reset.Time.Sub(r.now())
+		if delay < 0 {
+			delay = 0
+		}
+		// reset time has a second precision
+		return delay + time.Second, true
+
+	case errors.As(err, &abuseRateLimitErr):
+		if abuseRateLimitErr.RetryAfter != nil {
+			return *abuseRateLimitErr.RetryAfter, true
+		}
+		return r.backoff(attempt), true
+
+	case errors.As(err, &errorResponse):
+		if errorResponse.Response != nil && errorResponse.Response.StatusCode >= http.StatusInternalServerError {
+			return r.backoff(attempt), true
+		}
+	}
+
+	return 0, false
+}
+
+// backoff returns exponential delay with jitter for the given attempt.
+func (r *retrier) backoff(attempt int) time.Duration {
+	delay := r.BaseDelay << attempt
+	if delay > r.MaxDelay || delay <= 0 {
+		delay = r.MaxDelay
+	}
+
+	return r.jitter(delay)
+}
+
+// logQuota logs remaining rate limit every time another 10% of it is used.
+func (r *retrier) logQuota(response *github.Response) {
+	if response == nil || response.Rate.Limit == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rate := response.Rate
+	if r.quotaLogged && rate.Reset.Equal(r.lastQuota.Reset) && r.lastQuota.Remaining-rate.Remaining < rate.Limit/10 {
+		return
+	}
+
+	r.quotaLogged = true
+	r.lastQuota = rate
+	log.Printf("GitHub API quota: %d of %d requests left, resets at %s", rate.Remaining, rate.Limit, rate.Reset.Format(time.Kitchen))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// fullJitter returns random duration between d/2 and d.
+func fullJitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// parseRate parses rate limit headers of GitHub API response.
+func parseRate(r *http.Response) github.Rate {
+	var rate github.Rate
+	rate.Limit, _ = strconv.Atoi(r.Header.Get("X-RateLimit-Limit"))
+	rate.Remaining, _ = strconv.Atoi(r.Header.Get("X-RateLimit-Remaining"))
+	if reset, err := strconv.ParseInt(r.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rate.Reset = github.Timestamp{Time: time.Unix(reset, 0)}
+	}
+
+	return rate
+}
+
+type retryOrganizationsService struct {
+	organizationsService
+	retrier *retrier
+}
+
+func (s *retryOrganizationsService) Get(ctx context.Context, org string) (*github.Organization, *github.Response, error) {
+	return retry(ctx, s.retrier, func() (*github.Organization, *github.Response, error) {
+		return s.organizationsService.Get(ctx, org)
+	})
+}
+
+func (s *retryOrganizationsService) ListMembers(ctx context.Context, org string, opt *github.ListMembersOptions) ([]*github.User, *github.Response, error) {
+	return retry(ctx, s.retrier, func() ([]*github.User, *github.Response, error) {
+		return s.organizationsService.ListMembers(ctx, org, opt)
+	})
+}
+
+type retryTeamsService struct {
+	teamsService
+	retrier *retrier
+}
+
+func (s *retryTeamsService) ListTeams(ctx context.Context, org string, opt *github.ListOptions) ([]*github.Team, *github.Response, error) {
+	return retry(ctx, s.retrier, func() ([]*github.Team, *github.Response, error) {
+		return s.teamsService.ListTeams(ctx, org, opt)
+	})
+}
+
+func (s *retryTeamsService) ListTeamMembersByID(ctx context.Context, orgID, teamID int64, opts *github.TeamListTeamMembersOptions) ([]*github.User, *github.Response, error) {
+	return retry(ctx, s.retrier, func() ([]*github.User, *github.Response, error) {
+		return s.teamsService.ListTeamMembersByID(ctx, orgID, teamID, opts)
+	})
+}
+
+type retryGraphQLService struct {
+	graphqlService
+	retrier *retrier
+}
+
+func (s *retryGraphQLService) Query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	_, _, err := retry(ctx, s.retrier, func() (struct{}, *github.Response, error) {
+		data := &graphqlData{result: result}
+		err := s.graphqlService.Query(ctx, query, variables, data)
+		return struct{}{}, data.response(), err
+	})
+
+	return err
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRetrier(maxRetries int, sleeps *[]time.Duration) *retrier {
+	r := newRetrier(maxRetries)
+	r.now = func() time.Time { return time.Unix(1000, 0) }
+	r.jitter = func(d time.Duration) time.Duration { return d }
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return ctx.Err()
+	}
+
+	return r
+}
+
+func TestShouldRetryRateLimitedRequests(t *testing.T) {
+	retryAfter := 30 * time.Second
+
+	mockTS := new(mockTeamsService)
+	mockTS.On("ListTeams", mock.Anything, "test-org", mock.Anything).Return([]*github.Team{}, &github.Response{}, &github.RateLimitError{
+		Rate: github.Rate{Reset: github.Timestamp{Time: time.Unix(1060, 0)}},
+	}).Once()
+	mockTS.On("ListTeams", mock.Anything, "test-org", mock.Anything).Return([]*github.Team{}, &github.Response{}, &github.AbuseRateLimitError{
+		RetryAfter: &retryAfter,
+	}).Once()
+	mockTS.On("ListTeams", mock.Anything, "test-org", mock.Anything).Return([]*github.Team{}, &github.Response{}, &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusBadGateway},
+	}).Twice()
+	mockTS.On("ListTeams", mock.Anything, "test-org", mock.Anything).Return([]*github.Team{
+		{ID: github.Int64(1), Name: github.String("test-team")},
+	}, &github.Response{}, nil).Once()
+
+	var sleeps []time.Duration
+	service := &retryTeamsService{mockTS, newTestRetrier(5, &sleeps)}
+
+	teams, _, err := service.ListTeams(context.Background(), "test-org", nil)
+	if err != nil {
+		t.Errorf("Error listing teams: %v", err)
+	}
+	if len(teams) != 1 {
+		t.Errorf("Expected 1 team, got %v", teams)
+	}
+
+	expected := []time.Duration{61 * time.Second, 30 * time.Second, 4 * time.Second, 8 * time.Second}
+	if !reflect.DeepEqual(sleeps, expected) {
+		t.Errorf("Expected sleeps to be %v, got %v", expected, sleeps)
+	}
+}
+
+func TestShouldNotRetryClientErrors(t *testing.T) {
+	mockOS := new(mockOrganizationsService)
+	mockOS.On("Get", mock.Anything, "forbidden-org").Return(&github.Organization{}, &github.Response{}, &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusForbidden},
+	}).Once()
+	mockOS.On("Get", mock.Anything, "bad-org").Return(&github.Organization{}, &github.Response{}, fmt.Errorf("error")).Once()
+
+	var sleeps []time.Duration
+	service := &retryOrganizationsService{mockOS, newTestRetrier(5, &sleeps)}
+
+	if _, _, err := service.Get(context.Background(), "forbidden-org"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	if _, _, err := service.Get(context.Background(), "bad-org"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	if len(sleeps) != 0 {
+		t.Errorf("Expected no retries, got %v", sleeps)
+	}
+}
+
+func TestShouldGiveUpAfterMaxRetries(t *testing.T) {
+	mockGS := new(mockGraphQLService)
+	mockGS.On("Query", mock.Anything, "query", mock.Anything).Return("", &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusServiceUnavailable},
+	})
+
+	var sleeps []time.Duration
+	service := &retryGraphQLService{mockGS, newTestRetrier(2, &sleeps)}
+
+	if err := service.Query(context.Background(), "query", nil, nil); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	mockGS.AssertNumberOfCalls(t, "Query", 3)
+	if len(sleeps) != 2 {
+		t.Errorf("Expected 2 retries, got %v", sleeps)
+	}
+}
+
+func TestShouldKeepGraphQLRateLimit(t *testing.T) {
+	mockGS := new(mockGraphQLService)
+	mockGS.On("Query", mock.Anything, "query", mock.Anything).Return(`{
+		"rateLimit": {"limit": 5000, "remaining": 4990, "resetAt": "2022-10-01T12:00:00Z"},
+		"organization": {"login": "test-org"}
+	}`, nil)
+
+	var sleeps []time.Duration
+	r := newTestRetrier(0, &sleeps)
+	service := &retryGraphQLService{mockGS, r}
+
+	var result struct {
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+	}
+	if err := service.Query(context.Background(), "query", nil, &result); err != nil {
+		t.Fatalf("Error querying: %v", err)
+	}
+	if result.Organization.Login != "test-org" {
+		t.Errorf("Expected organization login to be test-org, got %q", result.Organization.Login)
+	}
+
+	expected := github.Rate{Limit: 5000, Remaining: 4990, Reset: github.Timestamp{Time: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)}}
+	if !r.quotaLogged || !reflect.DeepEqual(r.lastQuota, expected) {
+		t.Errorf("Expected quota %v to be logged, got %v", expected, r.lastQuota)
+	}
+}
+
+func TestShouldStopRetryingWhenCancelled(t *testing.T) {
+	mockTS := new(mockTeamsService)
+	mockTS.On("ListTeamMembersByID", mock.Anything, int64(123), int64(1), mock.Anything).Return([]*github.User{}, &github.Response{}, &github.AbuseRateLimitError{})
+
+	var sleeps []time.Duration
+	service := &retryTeamsService{mockTS, newTestRetrier(5, &sleeps)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := service.ListTeamMembersByID(ctx, 123, 1, nil); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	mockTS.AssertNumberOfCalls(t, "ListTeamMembersByID", 1)
+}
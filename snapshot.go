@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// snapshotVersion is incremented on incompatible changes of the snapshot format.
+const snapshotVersion = 1
+
+// Snapshot is organization data fetched from GitHub,
+// saved to be reused without calling GitHub API again.
+type Snapshot struct {
+	Version      int           `json:"version"`
+	StartedAt    time.Time     `json:"started_at"`             // when fetching started
+	FinishedAt   time.Time     `json:"finished_at"`            // when fetching finished
+	HideMembers  bool          `json:"hide_members,omitempty"` // members were not fetched
+	Organization *Organization `json:"organization"`
+}
+
+func newSnapshot(org *Organization, startedAt, finishedAt time.Time) *Snapshot {
+	return &Snapshot{
+		Version:      snapshotVersion,
+		StartedAt:    startedAt.UTC(),
+		FinishedAt:   finishedAt.UTC(),
+		Organization: org,
+	}
+}
+
+// writeSnapshot saves snapshot as JSON document to the given file.
+func writeSnapshot(path string, snapshot *Snapshot) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(snapshot)
+}
+
+// readSnapshot loads snapshot saved by writeSnapshot.
+func readSnapshot(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var snapshot Snapshot
+	if err := json.NewDecoder(f).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, snapshotVersion)
+	}
+
+	if snapshot.Organization == nil {
+		return nil, fmt.Errorf("snapshot has no organization")
+	}
+
+	if snapshot.Organization.Teams == nil {
+		snapshot.Organization.Teams = map[string]*Team{}
+	}
+
+	return &snapshot, nil
+}
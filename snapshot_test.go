@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestShouldWriteSnapshot(t *testing.T) {
+	org := &Organization{
+		ID:    123,
+		Login: "test-org",
+		Teams: map[string]*Team{
+			"test-team": {
+				ID:      1,
+				Slug:    "test-team",
+				Name:    "Test Team",
+				Privacy: "closed",
+				Members: []Member{{Login: "test-user", Role: RoleMaintainer}},
+			},
+			"test-team-2": {
+				ID:      2,
+				Slug:    "test-team-2",
+				Name:    "Test Team 2",
+				Parent:  "test-team",
+				Members: []Member{{Login: "test-user", Role: RoleMember}},
+			},
+		},
+		Members: []string{"test-user", "test-user-2"},
+	}
+
+	startedAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	snapshot := newSnapshot(org, startedAt, startedAt.Add(time.Minute))
+
+	path := filepath.Join(t.TempDir(), "org.json")
+	if err := writeSnapshot(path, snapshot); err != nil {
+		t.Fatalf("Error writing snapshot: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading snapshot: %v", err)
+	}
+
+	expected := `{
+  "version": 1,
+  "started_at": "2023-01-02T03:04:05Z",
+  "finished_at": "2023-01-02T03:05:05Z",
+  "organization": {
+    "id": 123,
+    "login": "test-org",
+    "teams": {
+      "test-team": {
+        "id": 1,
+        "slug": "test-team",
+        "name": "Test Team",
+        "privacy": "closed",
+        "members": [
+          {
+            "login": "test-user",
+            "role": "maintainer"
+          }
+        ]
+      },
+      "test-team-2": {
+        "id": 2,
+        "slug": "test-team-2",
+        "name": "Test Team 2",
+        "parent": "test-team",
+        "members": [
+          {
+            "login": "test-user",
+            "role": "member"
+          }
+        ]
+      }
+    },
+    "members": [
+      "test-user",
+      "test-user-2"
+    ]
+  }
+}
+`
+
+	if string(b) != expected {
+		t.Errorf("Expected snapshot to be:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func TestShouldReadSnapshot(t *testing.T) {
+	org := &Organization{
+		ID:    123,
+		Login: "test-org",
+		Teams: newTestTeams(map[string][]string{
+			"test-team": {"test-user"},
+		}),
+		Members: []string{"test-user"},
+	}
+
+	startedAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	expected := newSnapshot(org, startedAt, startedAt)
+
+	path := filepath.Join(t.TempDir(), "org.json")
+	if err := writeSnapshot(path, expected); err != nil {
+		t.Fatalf("Error writing snapshot: %v", err)
+	}
+
+	snapshot, err := readSnapshot(path)
+	if err != nil {
+		t.Fatalf("Error reading snapshot: %v", err)
+	}
+
+	if !reflect.DeepEqual(snapshot, expected) {
+		t.Errorf("Expected snapshot to be %v, got %v", expected, snapshot)
+	}
+}
+
+func TestShouldFailReadingUnsupportedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"future.json":  `{"version": 999, "organization": {"login": "test-org"}}`,
+		"empty.json":   `{"version": 1}`,
+		"invalid.json": `{"version":`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Error writing snapshot: %v", err)
+		}
+
+		if _, err := readSnapshot(path); err == nil {
+			t.Errorf("Expected error for %s, got nil", name)
+		}
+	}
+}
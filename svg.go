@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// SVG diagram dimensions in pixels, for monospace font.
+const (
+	svgFontSize   = 12.0
+	svgCharWidth  = 7.2 // average width of monospace character
+	svgLineHeight = 16.0
+	svgPadding    = 6.0
+)
+
+// svgNode is a team, or members without team, drawn as a box with a list of logins.
+type svgNode struct {
+	Title        string
+	Lines        []string
+	NoMaintainer bool // team has members but no maintainers
+	NoTeam       bool // members without team
+}
+
+// writeSVG draws teams as a layered diagram, like dot format rendered by Graphviz:
+// parent teams are connected to child teams with solid edges,
+// teams are connected to their subsets with dashed edges,
+// teams with identical members with dashed edges without arrows.
+func writeSVG(w io.Writer, d data) error {
+	var nodes []svgNode
+	index := map[string]int{}
+	for _, slug := range sortedKeys(d.Teams) {
+		team := d.Teams[slug]
+		node := svgNode{
+			Title:        team.Name,
+			NoMaintainer: len(team.Members) > 0 && len(team.Maintainers()) == 0,
+		}
+		for _, member := range team.Members {
+			if member.IsMaintainer() {
+				node.Lines = append(node.Lines, "★ "+member.Login)
+			} else {
+				node.Lines = append(node.Lines, member.Login)
+			}
+		}
+
+		index[slug] = len(nodes)
+		nodes = append(nodes, node)
+	}
+
+	if len(d.MembersWithoutTeam) > 0 {
+		nodes = append(nodes, svgNode{Title: "NO_TEAM", Lines: d.MembersWithoutTeam, NoTeam: true})
+	}
+
+	l := &layeredLayout{}
+	for _, node := range nodes {
+		width := utf8.RuneCountInString(node.Title)
+		for _, line := range node.Lines {
+			if n := utf8.RuneCountInString(line); n > width {
+				width = n
+			}
+		}
+
+		l.Nodes = append(l.Nodes, &layoutNode{
+			Width:  float64(width)*svgCharWidth + 2*svgPadding,
+			Height: float64(len(node.Lines)+1)*svgLineHeight + 2*svgPadding,
+		})
+	}
+
+	dashed := map[*layoutEdge]bool{}
+	for _, slug := range sortedKeys(d.Teams) {
+		team := d.Teams[slug]
+		if parent, ok := index[team.Parent]; ok {
+			l.Edges = append(l.Edges, &layoutEdge{From: parent, To: index[slug]})
+		}
+	}
+
+	same := map[*layoutEdge]bool{}
+	for _, edge := range d.SubsetEdges() {
+		e := &layoutEdge{From: index[edge.From], To: index[edge.To]}
+		dashed[e] = true
+		same[e] = edge.Same
+		l.Edges = append(l.Edges, e)
+	}
+
+	l.Run()
+
+	ew := &errWriter{w: w}
+	ew.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="monospace" font-size="%.0f">`+"\n",
+		l.Width, l.Height, l.Width, l.Height, svgFontSize)
+	ew.printf(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z"/></marker></defs>` + "\n")
+	ew.printf(`<rect width="100%%" height="100%%" fill="white"/>` + "\n")
+
+	for _, e := range l.Edges {
+		if len(e.Points) < 2 {
+			continue
+		}
+
+		style := `stroke-width="1.5"`
+		if dashed[e] {
+			style = `stroke-width="1" stroke-dasharray="5,4"`
+		}
+		if !same[e] {
+			style += ` marker-end="url(#arrow)"`
+		}
+		ew.printf(`<path d="%s" fill="none" stroke="black" %s/>`+"\n", svgPath(e.Points), style)
+	}
+
+	for i, node := range nodes {
+		ln := l.Nodes[i]
+
+		stroke := "black"
+		if node.NoMaintainer {
+			stroke = "red"
+		}
+		dash := ""
+		if node.NoTeam {
+			dash = ` stroke-dasharray="4,3"`
+		}
+
+		ew.printf(`<g>` + "\n")
+		ew.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="3" fill="white" stroke="%s" stroke-width="1.5"%s/>`+"\n",
+			ln.X, ln.Y, ln.Width, ln.Height, stroke, dash)
+
+		textY := ln.Y + svgPadding + svgFontSize
+		ew.printf(`<text x="%.1f" y="%.1f" font-weight="bold">%s</text>`+"\n", ln.X+svgPadding, textY, html.EscapeString(node.Title))
+		if len(node.Lines) > 0 {
+			lineY := ln.Y + svgPadding + svgLineHeight + 2
+			ew.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n", ln.X, lineY, ln.X+ln.Width, lineY, stroke)
+		}
+		for j, line := range node.Lines {
+			ew.printf(`<text x="%.1f" y="%.1f">%s</text>`+"\n", ln.X+svgPadding, textY+float64(j+1)*svgLineHeight, html.EscapeString(line))
+		}
+		ew.printf(`</g>` + "\n")
+	}
+
+	ew.printf("</svg>\n")
+
+	return ew.err
+}
+
+// svgPath returns SVG path through the points,
+// with vertical curves between layers.
+func svgPath(points []layoutPoint) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "M %.1f %.1f", points[0].X, points[0].Y)
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		if from.X == to.X {
+			fmt.Fprintf(&b, " L %.1f %.1f", to.X, to.Y)
+			continue
+		}
+
+		middle := (from.Y + to.Y) / 2
+		fmt.Fprintf(&b, " C %.1f %.1f %.1f %.1f %.1f %.1f", from.X, middle, to.X, middle, to.X, to.Y)
+	}
+
+	return b.String()
+}
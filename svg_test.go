@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestShouldWriteSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeSVG(&b, newData(newTestOrganization())); err != nil {
+		t.Fatalf("Error writing SVG: %v", err)
+	}
+
+	decoder := xml.NewDecoder(&b)
+	var texts []string
+	paths := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("Error decoding SVG: %v", err)
+			}
+			break
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Local == "path" {
+				paths++
+			}
+		case xml.CharData:
+			if text := strings.TrimSpace(string(token)); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+
+	expected := `Test "Team" | <1> & co,★ test-user,test-user-2,test-team-2,test-user,test-team-3,test-user-2,NO_TEAM,test-user-3`
+	if got := strings.Join(texts, ","); got != expected {
+		t.Errorf("Expected SVG texts to be %q, got %q", expected, got)
+	}
+
+	// arrow marker, parent edge and subset edge of test-team-3
+	if paths != 3 {
+		t.Errorf("Expected 3 paths, got %d", paths)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// teamsFile is a tree of teams in teams.yaml format,
+// as in https://github.com/shiny-platypus/demo-universe/blob/main/teams.yaml.
+type teamsFile struct {
+	Organization string          `yaml:"organization,omitempty"`
+	Teams        []teamsFileTeam `yaml:"teams"`
+	Members      []string        `yaml:"members,omitempty"` // organization members
+}
+
+type teamsFileTeam struct {
+	Name        string          `yaml:"name"`
+	Slug        string          `yaml:"slug,omitempty"` // derived from name if empty
+	Description string          `yaml:"description,omitempty"`
+	Privacy     string          `yaml:"privacy,omitempty"`
+	Maintainers []string        `yaml:"maintainers,omitempty"`
+	Members     []string        `yaml:"members,omitempty"`
+	Teams       []teamsFileTeam `yaml:"teams,omitempty"` // child teams
+}
+
+// writeTeamsYAML writes teams as a tree in teams.yaml format.
+// Members of child teams are listed only in child teams.
+func writeTeamsYAML(w io.Writer, d data) error {
+	children := childTeams(d.Teams)
+	for _, slugs := range children {
+		sort.Strings(slugs)
+	}
+
+	var f teamsFile
+	f.Organization = d.Org.Login
+	f.Members = d.Members
+
+	written := map[string]struct{}{}
+	var tree func(slug string) teamsFileTeam
+	tree = func(slug string) teamsFileTeam {
+		written[slug] = struct{}{}
+		team := d.Teams[slug]
+		cells := teamCells(d.Teams, children, slug)
+
+		t := teamsFileTeam{
+			Name:        team.Name,
+			Description: team.Description,
+			Privacy:     team.Privacy,
+		}
+		if slug != slugify(team.Name) {
+			t.Slug = slug
+		}
+
+		for _, member := range team.Members {
+			switch cells[member.Login] {
+			case cellMaintainer:
+				t.Maintainers = append(t.Maintainers, member.Login)
+			case cellMember:
+				t.Members = append(t.Members, member.Login)
+			}
+		}
+
+		for _, child := range children[slug] {
+			if _, ok := written[child]; !ok {
+				t.Teams = append(t.Teams, tree(child))
+			}
+		}
+
+		return t
+	}
+
+	for _, slug := range topLevelTeams(d.Teams, children) {
+		f.Teams = append(f.Teams, tree(slug))
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// readTeamsYAML loads organization from teams.yaml file.
+// Like GitHub API, members of child teams are added to their parent teams.
+func readTeamsYAML(path string) (*Organization, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var f teamsFile
+	if err := yaml.NewDecoder(file).Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to decode teams file: %w", err)
+	}
+
+	org := &Organization{
+		Login: f.Organization,
+		Teams: map[string]*Team{},
+	}
+
+	var add func(t teamsFileTeam, parent string) (map[string]string, error)
+	add = func(t teamsFileTeam, parent string) (map[string]string, error) {
+		if t.Name == "" {
+			return nil, fmt.Errorf("team without name")
+		}
+
+		slug := t.Slug
+		if slug == "" {
+			slug = slugify(t.Name)
+		}
+		if _, ok := org.Teams[slug]; ok {
+			return nil, fmt.Errorf("duplicate team %q", slug)
+		}
+
+		team := &Team{
+			Slug:        slug,
+			Name:        t.Name,
+			Description: t.Description,
+			Privacy:     t.Privacy,
+			Parent:      parent,
+		}
+		org.Teams[slug] = team
+
+		// roles by login, including members of child teams
+		roles := map[string]string{}
+		for _, child := range t.Teams {
+			childRoles, err := add(child, slug)
+			if err != nil {
+				return nil, err
+			}
+			for login := range childRoles {
+				roles[login] = RoleMember
+			}
+		}
+		for _, login := range t.Members {
+			roles[login] = RoleMember
+		}
+		for _, login := range t.Maintainers {
+			roles[login] = RoleMaintainer
+		}
+
+		for login, role := range roles {
+			team.Members = append(team.Members, Member{Login: login, Role: role})
+		}
+		sortMembers(team.Members)
+
+		return roles, nil
+	}
+
+	logins := map[string]struct{}{}
+	for _, t := range f.Teams {
+		roles, err := add(t, "")
+		if err != nil {
+			return nil, err
+		}
+		for login := range roles {
+			logins[login] = struct{}{}
+		}
+	}
+
+	org.Members = f.Members
+	if org.Members == nil {
+		for login := range logins {
+			org.Members = append(org.Members, login)
+		}
+		sort.Strings(org.Members)
+	}
+
+	return org, nil
+}
+
+// slugify returns team slug for the team name, the way GitHub does it.
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			if dash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+
+	return b.String()
+}
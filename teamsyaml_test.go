@@ -0,0 +1,101 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestShouldReadTeamsYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "teams.yaml")
+	err := os.WriteFile(path, []byte(`
+teams:
+  - name: Animals
+    maintainers: [joeduffy]
+    teams:
+      - name: Baby Unicorns
+        members: [susanev, joeduffy]
+  - name: ops
+    slug: operations
+    members: [thomas11]
+`), 0o644)
+	if err != nil {
+		t.Fatalf("Error writing teams file: %v", err)
+	}
+
+	org, err := readTeamsYAML(path)
+	if err != nil {
+		t.Fatalf("Error reading teams file: %v", err)
+	}
+
+	expected := &Organization{
+		Teams: map[string]*Team{
+			"animals": {
+				Slug:    "animals",
+				Name:    "Animals",
+				Members: []Member{{Login: "joeduffy", Role: RoleMaintainer}, {Login: "susanev", Role: RoleMember}},
+			},
+			"baby-unicorns": {
+				Slug:    "baby-unicorns",
+				Name:    "Baby Unicorns",
+				Parent:  "animals",
+				Members: []Member{{Login: "joeduffy", Role: RoleMember}, {Login: "susanev", Role: RoleMember}},
+			},
+			"operations": {
+				Slug:    "operations",
+				Name:    "ops",
+				Members: []Member{{Login: "thomas11", Role: RoleMember}},
+			},
+		},
+		Members: []string{"joeduffy", "susanev", "thomas11"},
+	}
+
+	if !reflect.DeepEqual(org, expected) {
+		t.Errorf("Expected organization to be %+v, got %+v", expected, org)
+	}
+}
+
+func TestShouldRoundTripTeamsYAML(t *testing.T) {
+	org := newTestOrganization()
+	for _, team := range org.Teams {
+		for i := range team.Members {
+			if team.Members[i].Role == "" {
+				team.Members[i].Role = RoleMember
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "teams.yaml")
+	err := writeOutput(path, func(w io.Writer) error {
+		return writeTeamsYAML(w, newData(org))
+	})
+	if err != nil {
+		t.Fatalf("Error writing teams file: %v", err)
+	}
+
+	got, err := readTeamsYAML(path)
+	if err != nil {
+		t.Fatalf("Error reading teams file: %v", err)
+	}
+
+	org.ID = 0
+	if !reflect.DeepEqual(got, org) {
+		t.Errorf("Expected organization to be %+v, got %+v", org, got)
+	}
+}
+
+func TestShouldSlugifyTeamNames(t *testing.T) {
+	tests := map[string]string{
+		"Baby Unicorns":   "baby-unicorns",
+		" Design & <UX> ": "design-ux",
+		"team_1":          "team_1",
+	}
+
+	for name, expected := range tests {
+		if got := slugify(name); got != expected {
+			t.Errorf("Expected slugify(%q) to be %q, got %q", name, expected, got)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// terraformMembership is a direct team membership managed by Terraform.
+type terraformMembership struct {
+	Team   string // team slug
+	Member Member
+	Name   string // resource name, unique among memberships
+}
+
+// terraformMemberships returns direct team memberships sorted by team slug and login.
+// Inherited members of child teams are skipped:
+// managing them in the parent team would add direct memberships.
+//
+// Resource names join team slug and login, so different pairs may get the same name
+// (team a_b with member c, and team a with member b_c),
+// later ones get a numeric suffix.
+func terraformMemberships(d data) []terraformMembership {
+	children := childTeams(d.Teams)
+	names := map[string]struct{}{}
+
+	var result []terraformMembership
+	for _, slug := range sortedKeys(d.Teams) {
+		cells := teamCells(d.Teams, children, slug)
+		for _, member := range d.Teams[slug].Members {
+			if cells[member.Login] == cellInherited {
+				continue
+			}
+
+			base := terraformName(slug + "_" + member.Login)
+			name := base
+			for i := 2; ; i++ {
+				if _, ok := names[name]; !ok {
+					break
+				}
+				name = fmt.Sprintf("%s_%d", base, i)
+			}
+			names[name] = struct{}{}
+
+			result = append(result, terraformMembership{Team: slug, Member: member, Name: name})
+		}
+	}
+
+	return result
+}
+
+// writeTerraform writes teams and their direct members
+// as github_team and github_team_membership Terraform resources.
+func writeTerraform(w io.Writer, d data) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("terraform {\n")
+	ew.printf("  required_providers {\n")
+	ew.printf("    github = {\n")
+	ew.printf("      source = \"integrations/github\"\n")
+	ew.printf("    }\n")
+	ew.printf("  }\n")
+	ew.printf("}\n\n")
+
+	ew.block(`provider "github"`, hclAttr{"owner", hclString(d.Org.Login)})
+
+	for _, slug := range sortedKeys(d.Teams) {
+		team := d.Teams[slug]
+
+		attrs := []hclAttr{{"name", hclString(team.Name)}}
+		if team.Description != "" {
+			attrs = append(attrs, hclAttr{"description", hclString(team.Description)})
+		}
+		if team.Privacy != "" {
+			attrs = append(attrs, hclAttr{"privacy", hclString(team.Privacy)})
+		}
+		if _, ok := d.Teams[team.Parent]; ok {
+			attrs = append(attrs, hclAttr{"parent_team_id", "github_team." + terraformName(team.Parent) + ".id"})
+		}
+
+		ew.printf("\n")
+		ew.block(fmt.Sprintf("resource \"github_team\" %q", terraformName(slug)), attrs...)
+	}
+
+	for _, m := range terraformMemberships(d) {
+		ew.printf("\n")
+		ew.block(
+			fmt.Sprintf("resource \"github_team_membership\" %q", m.Name),
+			hclAttr{"team_id", "github_team." + terraformName(m.Team) + ".id"},
+			hclAttr{"username", hclString(m.Member.Login)},
+			hclAttr{"role", hclString(terraformRole(m.Member.Role))},
+		)
+	}
+
+	if len(d.MembersWithoutTeam) > 0 {
+		ew.printf("\n# Organization members without team:\n")
+		for _, login := range d.MembersWithoutTeam {
+			ew.printf("# - %s\n", login)
+		}
+	}
+
+	return ew.err
+}
+
+// writeTerraformImports writes import blocks for resources of writeTerraform,
+// so existing teams are adopted into Terraform state instead of being recreated.
+func writeTerraformImports(w io.Writer, d data) error {
+	ew := &errWriter{w: w}
+
+	for i, slug := range sortedKeys(d.Teams) {
+		if i > 0 {
+			ew.printf("\n")
+		}
+		ew.block("import",
+			hclAttr{"to", "github_team." + terraformName(slug)},
+			hclAttr{"id", hclString(terraformTeamID(d.Teams[slug]))},
+		)
+	}
+
+	for _, m := range terraformMemberships(d) {
+		ew.printf("\n")
+		ew.block("import",
+			hclAttr{"to", "github_team_membership." + m.Name},
+			hclAttr{"id", hclString(terraformTeamID(d.Teams[m.Team]) + ":" + m.Member.Login)},
+		)
+	}
+
+	return ew.err
+}
+
+// terraformTeamID returns team ID for import, or team slug if ID is unknown.
+func terraformTeamID(team *Team) string {
+	if team.ID != 0 {
+		return strconv.FormatInt(team.ID, 10)
+	}
+
+	return team.Slug
+}
+
+// terraformRole returns github_team_membership role, member by default.
+func terraformRole(role string) string {
+	if role == RoleMaintainer {
+		return RoleMaintainer
+	}
+
+	return RoleMember
+}
+
+// terraformName returns s as a valid Terraform resource name.
+func terraformName(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9', r == '-':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	return b.String()
+}
+
+// hclString returns s as a quoted HCL string,
+// escaping template sequences so they are not interpolated.
+func hclString(s string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	).Replace(s) + `"`
+}
+
+// errWriter remembers the first write error and skips further writes.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
+
+// hclAttr is an attribute of HCL block with already formatted value.
+type hclAttr struct {
+	Name  string
+	Value string
+}
+
+// block writes HCL block with attributes aligned like terraform fmt does.
+func (ew *errWriter) block(header string, attrs ...hclAttr) {
+	width := 0
+	for _, attr := range attrs {
+		if len(attr.Name) > width {
+			width = len(attr.Name)
+		}
+	}
+
+	ew.printf("%s {\n", header)
+	for _, attr := range attrs {
+		ew.printf("  %-*s = %s\n", width, attr.Name, attr.Value)
+	}
+	ew.printf("}\n")
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestShouldWriteTerraform(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"parent": {"alice", "bob"},
+		"child":  {"bob"},
+	})
+	teams["parent"].Name = `Parent "${team}"`
+	teams["parent"].Privacy = "closed"
+	teams["parent"].Members[0].Role = RoleMaintainer
+	teams["child"].Parent = "parent"
+
+	var b bytes.Buffer
+	if err := writeTerraform(&b, newData(&Organization{Login: "test-org", Teams: teams})); err != nil {
+		t.Fatalf("Error writing Terraform: %v", err)
+	}
+
+	expected := `terraform {
+  required_providers {
+    github = {
+      source = "integrations/github"
+    }
+  }
+}
+
+provider "github" {
+  owner = "test-org"
+}
+
+resource "github_team" "child" {
+  name           = "child"
+  parent_team_id = github_team.parent.id
+}
+
+resource "github_team" "parent" {
+  name    = "Parent \"$${team}\""
+  privacy = "closed"
+}
+
+resource "github_team_membership" "child_bob" {
+  team_id  = github_team.child.id
+  username = "bob"
+  role     = "member"
+}
+
+resource "github_team_membership" "parent_alice" {
+  team_id  = github_team.parent.id
+  username = "alice"
+  role     = "maintainer"
+}
+`
+	if b.String() != expected {
+		t.Errorf("Expected Terraform to be:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestShouldWriteTerraformImports(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"parent": {"alice", "bob"},
+		"child":  {"bob"},
+	})
+	teams["parent"].ID = 1
+	teams["child"].Parent = "parent"
+
+	var b bytes.Buffer
+	if err := writeTerraformImports(&b, newData(&Organization{Teams: teams})); err != nil {
+		t.Fatalf("Error writing Terraform imports: %v", err)
+	}
+
+	expected := `import {
+  to = github_team.child
+  id = "child"
+}
+
+import {
+  to = github_team.parent
+  id = "1"
+}
+
+import {
+  to = github_team_membership.child_bob
+  id = "child:bob"
+}
+
+import {
+  to = github_team_membership.parent_alice
+  id = "1:alice"
+}
+`
+	if b.String() != expected {
+		t.Errorf("Expected Terraform imports to be:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestShouldNameTerraformMembershipsUniquely(t *testing.T) {
+	teams := newTestTeams(map[string][]string{
+		"a_b": {"c"},
+		"a":   {"b_c"},
+	})
+
+	var names []string
+	for _, m := range terraformMemberships(newData(&Organization{Teams: teams})) {
+		names = append(names, m.Name)
+	}
+
+	expected := []string{"a_b_c", "a_b_c_2"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected membership names to be %v, got %v", expected, names)
+	}
+}
+
+func TestShouldFindTerraformImportsPath(t *testing.T) {
+	tests := []struct {
+		imports, output, expected string
+	}{
+		{"", "github/teams.tf", "github/imports.tf"},
+		{"", "-", "-"},
+		{"other.tf", "-", "other.tf"},
+		{"-", "github/teams.tf", "-"},
+	}
+
+	for _, test := range tests {
+		if got := terraformImportsPath(test.imports, test.output); got != test.expected {
+			t.Errorf("Expected imports of %q with --terraform-imports=%q to be written to %q, got %q", test.output, test.imports, test.expected, got)
+		}
+	}
+}
+
+func TestShouldFormatTerraformNames(t *testing.T) {
+	tests := map[string]string{
+		"team-1":   "team-1",
+		"1st-team": "_1st-team",
+		"a.b c":    "a_b_c",
+	}
+
+	for s, expected := range tests {
+		if got := terraformName(s); got != expected {
+			t.Errorf("Expected terraformName(%q) to be %q, got %q", s, expected, got)
+		}
+	}
+}